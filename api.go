@@ -1,12 +1,15 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"time"
+	"net/url"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
 )
 
 // this code file handles api
@@ -14,6 +17,88 @@ import (
 // https://api1.raildata.org.uk/1010-live-departure-board-dep1_2/LDBWS/api/20220120/GetDepBoardWithDetails/RDG
 // https://api1.raildata.org.uk/1010-live-arrival-board-arr/LDBWS/api/20220120/GetArrBoardWithDetails/RDG
 // https://api1.raildata.org.uk/1010-service-details1_2/LDBWS/api/20220120/GetServiceDetails/{serviceid}
+// https://api1.raildata.org.uk/1010-live-departure-board-dep1_2/LDBWS/api/20220120/GetNextDeparturesWithDetails/RDG?filterList=BRI,OXF
+
+// placeholder key in a fresh settings file
+const default_key string = "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"
+
+// where the data comes from, chosen in settings
+const (
+	source_rdm    string = "rdm"    // Rail Data Marketplace REST api
+	source_darwin string = "darwin" // legacy Darwin OpenLDBWS SOAP api
+)
+
+// DepartureSource is anything that can give boards and service details
+type DepartureSource interface {
+	Departures(ctx context.Context, q board_query) (station_board, error)
+	Arrivals(ctx context.Context, q board_query) (station_board, error)
+	NextDepartures(ctx context.Context, q board_query) (departures_board, error)    // uses filter_list
+	FastestDepartures(ctx context.Context, q board_query) (departures_board, error) // uses filter_list
+	ServiceDetails(ctx context.Context, service_id string) (service_details, error)
+}
+
+// parameters of one board request
+type board_query struct {
+	crs         string
+	filter_crs  string // "*" for no filter
+	filter_type string // "to" or "from"
+	num_rows    int
+	filter_list []string // destinations of next and fastest departures
+	time_offset int      // minutes, 0 for now
+	time_window int      // minutes, 0 for the api default
+}
+
+// api limits of timeOffset and timeWindow
+const (
+	min_time_offset int = -120
+	max_time_offset int = 119
+	max_time_window int = 120
+)
+
+// optional parameters and their values, in the order the soap api wants them
+func (q board_query) time_params() ([]string, []string) {
+	var names, vals []string
+	if q.time_offset != 0 {
+		names = append(names, "timeOffset")
+		vals = append(vals, fmt.Sprint(q.time_offset))
+	}
+	if q.time_window > 0 {
+		names = append(names, "timeWindow")
+		vals = append(vals, fmt.Sprint(q.time_window))
+	}
+	return names, vals
+}
+
+// the source chosen in settings, behind the response cache
+func new_source(s settings, rootURI fyne.URI) (DepartureSource, error) {
+	if s.Replay != "" {
+		return replay_source(s, rootURI)
+	}
+
+	client := api_client(s)
+	source := s.Source
+	if s.Demo {
+		// made up boards from the built in mock server, no keys or network needed
+		client = &http.Client{Transport: handler_transport{mock_handler{scenario: s.Demo_scenario}}}
+		source = "demo"
+	}
+	if s.Record {
+		client = &http.Client{Timeout: client.Timeout,
+			Transport: recording_transport{inner: transport_of(client), source: source, secret: s.Darwin_token, rootURI: rootURI}}
+	}
+
+	switch {
+	case s.Demo:
+		src := rdm_source{dep_key: "demo", arr_key: "demo", svc_key: "demo", client: client}
+		return new_cached_source(src, "demo-"+s.Demo_scenario, s, rootURI), nil
+	case source == source_darwin:
+		src := darwin_source{token: s.Darwin_token, client: client}
+		return new_cached_source(src, source_darwin, s, rootURI), nil
+	default:
+		src := rdm_source{dep_key: s.Key, arr_key: s.Arr_key, svc_key: s.Svc_key, client: client}
+		return new_cached_source(src, source_rdm, s, rootURI), nil
+	}
+}
 
 // ?sth=idk&thing=idk_either
 func format_params(param_list []string, val_list []string) (string, error) {
@@ -31,104 +116,217 @@ func format_params(param_list []string, val_list []string) (string, error) {
 
 }
 
-func request(url, key string) ([]train_service, error) {
-	if key == "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx" {
-		return nil, nil // default key, don't even bother sending request
+// send a GET request with the api key in the header, return the body
+// product and crs are only used to explain errors
+func fetch(ctx context.Context, client *http.Client, url, key, product, crs string) ([]byte, error) {
+	if key == "" {
+		return nil, &key_error{product: product, missing: true}
+	}
+	build := func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("x-apikey", key) // put api key in header
+		return req, nil
 	}
-	req, err := http.NewRequest("GET", url, nil)
 
-	if err != nil {
-		return nil, err
+	status, body, err := send(ctx, client, build, retry_server_errors)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	} else if err != nil {
+		return nil, &offline_error{err: err}
 	}
+	if status != 200 {
+		return nil, classify_status(status, body, product, crs)
+	}
+	return body, nil
+}
+
+// Rail Data Marketplace, one key per product
+type rdm_source struct {
+	dep_key string
+	arr_key string
+	svc_key string
+	client  *http.Client
+}
+
+// product names on Rail Data Marketplace
+const (
+	rdm_dep_product string = "Live Departure Board"
+	rdm_arr_product string = "Live Arrival Board"
+	rdm_svc_product string = "Service Details"
+)
+
+const (
+	rdm_dep_url string = "https://api1.raildata.org.uk/1010-live-departure-board-dep1_2/LDBWS/api/20220120/GetDepBoardWithDetails/"
+	rdm_arr_url string = "https://api1.raildata.org.uk/1010-live-arrival-board-arr/LDBWS/api/20220120/GetArrivalBoard/"
+	rdm_svc_url string = "https://api1.raildata.org.uk/1010-service-details1_2/LDBWS/api/20220120/GetServiceDetails/"
+
+	// part of the departure board product
+	rdm_next_url    string = "https://api1.raildata.org.uk/1010-live-departure-board-dep1_2/LDBWS/api/20220120/GetNextDeparturesWithDetails/"
+	rdm_fastest_url string = "https://api1.raildata.org.uk/1010-live-departure-board-dep1_2/LDBWS/api/20220120/GetFastestDeparturesWithDetails/"
+)
+
+// the api takes at most this many destinations
+const max_filter_list int = 10
 
-	req.Header.Set("x-apikey", key) // put api key in header
-	client := &http.Client{}
+func (src rdm_source) board(ctx context.Context, base_url, key, product string, q board_query) (station_board, error) {
+	if key == default_key {
+		return station_board{}, nil // default key, don't even bother sending request
+	}
 
-	res, err := client.Do(req)
+	var params string
+	var err error
+	time_names, time_vals := q.time_params()
+	if q.filter_crs != "*" {
+		params, err = format_params(append([]string{"filterCrs", "filterType", "numRows"}, time_names...),
+			append([]string{q.filter_crs, q.filter_type, fmt.Sprint(q.num_rows)}, time_vals...)) // has filter station
+	} else {
+		params, err = format_params(append([]string{"numRows"}, time_names...),
+			append([]string{fmt.Sprint(q.num_rows)}, time_vals...)) // no filter station
+	}
 	if err != nil {
-		return nil, err
+		return station_board{}, err
 	}
 
-	body, err := io.ReadAll(res.Body)
-	if res.StatusCode != 200 {
-		if res.StatusCode > 299 {
-			return nil, fmt.Errorf("HTTP Error Code %d", res.StatusCode)
-		} else {
-			return nil, fmt.Errorf("HTTP status code %d", res.StatusCode)
-		}
+	body, err := fetch(ctx, src.client, base_url+q.crs+params, key, product, q.crs)
+	var crs_err *unknown_crs_error
+	if errors.As(err, &crs_err) && q.filter_crs != "*" {
+		crs_err.crs = q.crs + " or " + q.filter_crs // can't tell which one
 	}
 	if err != nil {
-		return nil, err
+		return station_board{}, err
 	}
+	return decode_board(body)
+}
 
-	res_struct := return_data{}
+func (src rdm_source) Departures(ctx context.Context, q board_query) (station_board, error) {
+	return src.board(ctx, rdm_dep_url, src.dep_key, rdm_dep_product, q)
+}
 
-	json.Unmarshal(fmt.Appendf(nil, "%s", body), &res_struct)
-	services := make([]train_service, 0, len(res_struct.TrainServices))
+func (src rdm_source) Arrivals(ctx context.Context, q board_query) (station_board, error) {
+	return src.board(ctx, rdm_arr_url, src.arr_key, rdm_arr_product, q)
+}
 
-	for _, val := range res_struct.TrainServices {
-		thisService := val.(map[string]any)
-		thisDest := thisService["destination"].([]any)
-		thisDestInner := thisDest[0].(map[string]any)
+func (src rdm_source) departures(ctx context.Context, base_url string, q board_query) (departures_board, error) {
+	if src.dep_key == default_key {
+		return departures_board{}, nil // default key, don't even bother sending request
+	}
 
-		var new_service_struct train_service
-		if thisService["platform"] != nil {
-			new_service_struct.plat = thisService["platform"].(string)
-		} else {
-			new_service_struct.plat = "?"
-		}
-		// put data into defined structs
-		new_service_struct.std = thisService["std"].(string)
-		new_service_struct.etd = thisService["etd"].(string)
-		new_service_struct.operator = thisService["operator"].(string)
-		new_service_struct.dest = thisDestInner["crs"].(string)
-		new_service_struct.toc = thisService["operatorCode"].(string)
+	time_names, time_vals := q.time_params()
+	params, err := format_params(append([]string{"filterList"}, time_names...),
+		append([]string{strings.Join(q.filter_list, ",")}, time_vals...))
+	if err != nil {
+		return departures_board{}, err
+	}
 
-		services = append(services, new_service_struct)
+	body, err := fetch(ctx, src.client, base_url+q.crs+params, src.dep_key, rdm_dep_product, q.crs)
+	var crs_err *unknown_crs_error
+	if errors.As(err, &crs_err) {
+		crs_err.crs = strings.Join(append([]string{q.crs}, q.filter_list...), ", ") // can't tell which one
 	}
-	return services, nil
+	if err != nil {
+		return departures_board{}, err
+	}
+	return decode_departures(body)
 }
 
-func getLastSundayOfMonth(year int, month time.Month) time.Time {
-	// Get the first day of the *next* month.
-	// For example, if month is March, this gets April 1st.
-	firstDayOfNextMonth := time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+func (src rdm_source) NextDepartures(ctx context.Context, q board_query) (departures_board, error) {
+	return src.departures(ctx, rdm_next_url, q)
+}
 
-	// Go back one day to get the last day of the *current* month.
-	// For example, if firstDayOfNextMonth is April 1st, this gets March 31st.
-	lastDayOfCurrentMonth := firstDayOfNextMonth.AddDate(0, 0, -1)
+func (src rdm_source) FastestDepartures(ctx context.Context, q board_query) (departures_board, error) {
+	return src.departures(ctx, rdm_fastest_url, q)
+}
 
-	// Iterate backwards from the last day of the month until it's a Sunday.
-	currentDay := lastDayOfCurrentMonth
-	for currentDay.Weekday() != time.Sunday {
-		currentDay = currentDay.AddDate(0, 0, -1)
+func (src rdm_source) ServiceDetails(ctx context.Context, service_id string) (service_details, error) {
+	key := src.svc_key
+	if service_id == "" {
+		return service_details{}, errors.New("this service has no service ID")
+	} else if key == default_key {
+		key = "" // not set yet, let fetch complain
 	}
-	return currentDay
+	body, err := fetch(ctx, src.client, rdm_svc_url+url.PathEscape(service_id), key, rdm_svc_product, "")
+	if err != nil {
+		return service_details{}, err
+	}
+	return decode_details(body)
 }
 
-// IsUKUsingSummerTime determines if the UK is currently observing Summer Time (DST).
-// DST in the UK is between 1:00 AM (GMT) on the last Sunday in March
-// and 1:00 AM (GMT) on the last Sunday in October.
-// The function uses the current UTC time for its check.
-func IsUKUsingSummerTime() bool {
-	// Get the current time in UTC (GMT) as the DST rules are specified in GMT.
-	now := time.Now().UTC()
+// services of a board for the table, and its station messages as plain text
+// dest_crs is the filter destination of a departure board, or "*"
+// replacement buses and ferries are merged in by time unless hidden
+func board_services(sb station_board, dest_crs string, hide_replacements bool) ([]train_service, []string) {
+	messages := make([]string, 0, len(sb.NrccMessages))
+	for _, msg := range sb.NrccMessages {
+		if text := msg.text(); text != "" {
+			messages = append(messages, text)
+		}
+	}
 
-	year := now.Year()
+	all := sb.TrainServices
+	if !hide_replacements {
+		all = slices.Concat(sb.TrainServices, sb.BusServices, sb.FerryServices)
+	}
+	services := make([]train_service, 0, len(all))
+	for _, val := range all {
+		ts := val.to_train_service() // put data into defined structs
+		if cp, ok := val.calling_at(dest_crs); ok {
+			ts.dest_sta = cp.St
+			ts.dest_eta = cp.Et
+		}
+		services = append(services, ts)
+	}
+	if len(services) > len(sb.TrainServices) {
+		sort_by_time(services)
+	}
+	return services, messages
+}
 
-	// Calculate the start of DST for the current year.
-	// It's the last Sunday in March at 1:00 AM GMT.
-	lastSundayMarch := getLastSundayOfMonth(year, time.March)
-	dstStart := time.Date(year, lastSundayMarch.Month(), lastSundayMarch.Day(), 1, 0, 0, 0, time.UTC)
+// sort services by scheduled time, keeping the ones after midnight at the end
+func sort_by_time(services []train_service) {
+	first, _ := hhmm_minutes(first_of(services[0].std, services[0].sta))
+	key := func(ts train_service) int {
+		mins, ok := hhmm_minutes(first_of(ts.std, ts.sta))
+		if !ok {
+			return 2 * 24 * 60 // no time, last
+		} else if mins < first-12*60 {
+			mins += 24 * 60 // tomorrow
+		}
+		return mins
+	}
+	slices.SortStableFunc(services, func(a, b train_service) int {
+		return key(a) - key(b)
+	})
+}
 
-	// Calculate the end of DST for the current year.
-	// It's the last Sunday in October at 1:00 AM GMT.
-	lastSundayOctober := getLastSundayOfMonth(year, time.October)
-	dstEnd := time.Date(year, lastSundayOctober.Month(), lastSundayOctober.Day(), 1, 0, 0, 0, time.UTC)
+// one row per destination of a next or fastest departures board, in the order asked for
+// destinations without a train get a row saying so
+func departures_services(db departures_board, dests []string) ([]train_service, []string) {
+	messages := make([]string, 0, len(db.NrccMessages))
+	for _, msg := range db.NrccMessages {
+		if text := msg.text(); text != "" {
+			messages = append(messages, text)
+		}
+	}
 
-	// Check if the current time falls within the DST period.
-	// The period is inclusive at the start and exclusive at the end: [dstStart, dstEnd)
-	// This means 'now' must be greater than or equal to dstStart, AND
-	// 'now' must be strictly less than dstEnd.
-	return !now.Before(dstStart) && now.Before(dstEnd)
+	services := make([]train_service, 0, len(dests))
+	for _, crs := range dests {
+		ts := train_service{to_crs: crs, etd: "No train"}
+		for _, dep := range db.Departures {
+			if dep.Crs != crs || dep.Service == nil {
+				continue
+			}
+			ts = dep.Service.to_train_service()
+			ts.to_crs = crs
+			if cp, ok := dep.Service.calling_at(crs); ok {
+				ts.dest_sta = cp.St
+				ts.dest_eta = cp.Et
+			}
+			break
+		}
+		services = append(services, ts)
+	}
+	return services, messages
 }
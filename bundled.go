@@ -20,3 +20,8 @@ var resourceTocJson = &fyne.StaticResource{
 	StaticContent: []byte(
 		"{\n    \"version\": \"2022-05-17 12:00\",\n    \"TOCList\": [\n        {\n            \"toc\": \"AW\",\n            \"Value\": \"Transport for Wales\"\n        },\n        {\n            \"toc\": \"CC\",\n            \"Value\": \"c2c\"\n        },\n        {\n            \"toc\": \"CH\",\n            \"Value\": \"Chiltern Railways\"\n        },\n        {\n            \"toc\": \"CS\",\n            \"Value\": \"Caledonian Sleeper\"\n        },\n        {\n            \"toc\": \"EM\",\n            \"Value\": \"East Midlands Railway\"\n        },\n        {\n            \"toc\": \"ES\",\n            \"Value\": \"Eurostar\"\n        },\n        {\n            \"toc\": \"GC\",\n            \"Value\": \"Grand Central\"\n        },\n        {\n            \"toc\": \"GN\",\n            \"Value\": \"Great Northern\"\n        },\n        {\n            \"toc\": \"GR\",\n            \"Value\": \"London North Eastern Railway\"\n        },\n        {\n            \"toc\": \"GW\",\n            \"Value\": \"Great Western Railway\"\n        },\n        {\n            \"toc\": \"GX\",\n            \"Value\": \"Gatwick Express\"\n        },\n        {\n            \"toc\": \"HC\",\n            \"Value\": \"Heathrow Connect\"\n        },\n        {\n            \"toc\": \"HT\",\n            \"Value\": \"Hull Trains\"\n        },\n        {\n            \"toc\": \"HX\",\n            \"Value\": \"Heathrow Express\"\n        },\n        {\n            \"toc\": \"IL\",\n            \"Value\": \"Island Line\"\n        },\n        {\n            \"toc\": \"LD\",\n            \"Value\": \"Lumo\"\n        },\n        {\n            \"toc\": \"LE\",\n            \"Value\": \"Greater Anglia\"\n        },\n        {\n            \"toc\": \"LM\",\n            \"Value\": \"West Midlands Trains\"\n        },\n        {\n            \"toc\": \"LO\",\n            \"Value\": \"London Overground\"\n        },\n        {\n            \"toc\": \"LT\",\n            \"Value\": \"London Underground\"\n        },\n        {\n            \"toc\": \"ME\",\n            \"Value\": \"Merseyrail\"\n        },\n        {\n            \"toc\": \"NT\",\n            \"Value\": \"Northern\"\n        },\n        {\n            \"toc\": \"NY\",\n            \"Value\": \"North Yorkshire Moors Railway\"\n        },\n        {\n            \"toc\": \"PC\",\n            \"Value\": \"Private Charter\"\n        },\n        {\n            \"toc\": \"RT\",\n            \"Value\": \"Network Rail\"\n        },\n        {\n            \"toc\": \"SE\",\n            \"Value\": \"Southeastern\"\n        },\n        {\n            \"toc\": \"SJ\",\n            \"Value\": \"Sheffield Supertram\"\n        },\n        {\n            \"toc\": \"SN\",\n            \"Value\": \"Southern\"\n        },\n        {\n            \"toc\": \"SP\",\n            \"Value\": \"Swanage Railway\"\n        },\n        {\n            \"toc\": \"SR\",\n            \"Value\": \"ScotRail\"\n        },\n        {\n            \"toc\": \"SW\",\n            \"Value\": \"South Western Railway\"\n        },\n        {\n            \"toc\": \"TL\",\n            \"Value\": \"Thameslink\"\n        },\n        {\n            \"toc\": \"TP\",\n            \"Value\": \"TransPennine Express\"\n        },\n        {\n            \"toc\": \"TW\",\n            \"Value\": \"Tyne and Wear Metro\"\n        },\n        {\n            \"toc\": \"VT\",\n            \"Value\": \"Avanti West Coast\"\n        },\n        {\n            \"toc\": \"WR\",\n            \"Value\": \"West Coast Railway Co\"\n        },\n        {\n            \"toc\": \"XC\",\n            \"Value\": \"CrossCountry\"\n        },\n        {\n            \"toc\": \"XR\",\n            \"Value\": \"Elizabeth Line\"\n        },\n        {\n            \"toc\": \"ZB\",\n            \"Value\": \"Bus Station\"\n        },\n        {\n            \"toc\": \"ZF\",\n            \"Value\": \"Ferry Terminal\"\n        },\n        {\n            \"toc\": \"ZM\",\n            \"Value\": \"West Somerset Railway\"\n        },\n        {\n            \"toc\": \"ZZ\",\n            \"Value\": \"Unknown\"\n        }\n    ]\n}\n"),
 }
+var resourceBankHolidaysJson = &fyne.StaticResource{
+	StaticName: "bank_holidays.json",
+	StaticContent: []byte(
+		"{\"england-and-wales\":{\"division\":\"england-and-wales\",\"events\":[{\"title\":\"New Year’s Day\",\"date\":\"2018-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2018-03-30\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2018-04-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2018-05-07\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2018-05-28\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2018-08-27\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2018-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2018-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2019-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2019-04-19\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2019-04-22\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2019-05-06\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2019-05-27\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2019-08-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2019-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2019-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2020-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2020-04-10\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2020-04-13\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday (VE day)\",\"date\":\"2020-05-08\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2020-05-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2020-08-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2020-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2020-12-28\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2021-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2021-04-02\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2021-04-05\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2021-05-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2021-05-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2021-08-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2021-12-27\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2021-12-28\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2022-01-03\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2022-04-15\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2022-04-18\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2022-05-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2022-06-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Platinum Jubilee bank holiday\",\"date\":\"2022-06-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2022-08-29\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Bank Holiday for the State Funeral of Queen Elizabeth II\",\"date\":\"2022-09-19\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Boxing Day\",\"date\":\"2022-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2022-12-27\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2023-01-02\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2023-04-07\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2023-04-10\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2023-05-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Bank holiday for the coronation of King Charles III\",\"date\":\"2023-05-08\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2023-05-29\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2023-08-28\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2023-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2023-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2024-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2024-03-29\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2024-04-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2024-05-06\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2024-05-27\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2024-08-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2024-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2024-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2025-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2025-04-18\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2025-04-21\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2025-05-05\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2025-05-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2025-08-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2025-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2025-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2026-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2026-04-03\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2026-04-06\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2026-05-04\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2026-05-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2026-08-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2026-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2026-12-28\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2027-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2027-03-26\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2027-03-29\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2027-05-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2027-05-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2027-08-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2027-12-27\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2027-12-28\",\"notes\":\"Substitute day\",\"bunting\":true}]},\"scotland\":{\"division\":\"scotland\",\"events\":[{\"title\":\"New Year’s Day\",\"date\":\"2018-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2018-01-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2018-03-30\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday\",\"date\":\"2018-05-07\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2018-05-28\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2018-08-06\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Andrew’s Day\",\"date\":\"2018-11-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2018-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2018-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2019-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2019-01-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2019-04-19\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday\",\"date\":\"2019-05-06\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2019-05-27\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2019-08-05\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Andrew’s Day\",\"date\":\"2019-12-02\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2019-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2019-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2020-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2020-01-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2020-04-10\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday (VE day)\",\"date\":\"2020-05-08\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2020-05-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2020-08-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Andrew’s Day\",\"date\":\"2020-11-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2020-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2020-12-28\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2021-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2021-01-04\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2021-04-02\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday\",\"date\":\"2021-05-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2021-05-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2021-08-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Andrew’s Day\",\"date\":\"2021-11-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2021-12-27\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2021-12-28\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2022-01-03\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2022-01-04\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2022-04-15\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday\",\"date\":\"2022-05-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2022-06-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Platinum Jubilee bank holiday\",\"date\":\"2022-06-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2022-08-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Bank Holiday for the State Funeral of Queen Elizabeth II\",\"date\":\"2022-09-19\",\"notes\":\"\",\"bunting\":false},{\"title\":\"St Andrew’s Day\",\"date\":\"2022-11-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2022-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2022-12-27\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2023-01-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2023-01-03\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2023-04-07\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday\",\"date\":\"2023-05-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Bank holiday for the coronation of King Charles III\",\"date\":\"2023-05-08\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2023-05-29\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2023-08-07\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Andrew’s Day\",\"date\":\"2023-11-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2023-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2023-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2024-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2024-01-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2024-03-29\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday\",\"date\":\"2024-05-06\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2024-05-27\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2024-08-05\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Andrew’s Day\",\"date\":\"2024-12-02\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2024-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2024-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2025-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2025-01-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2025-04-18\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday\",\"date\":\"2025-05-05\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2025-05-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2025-08-04\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Andrew’s Day\",\"date\":\"2025-12-01\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2025-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2025-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2026-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2026-01-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2026-04-03\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday\",\"date\":\"2026-05-04\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2026-05-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2026-08-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Andrew’s Day\",\"date\":\"2026-11-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2026-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2026-12-28\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2027-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"2nd January\",\"date\":\"2027-01-04\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2027-03-26\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Early May bank holiday\",\"date\":\"2027-05-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2027-05-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2027-08-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Andrew’s Day\",\"date\":\"2027-11-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2027-12-27\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2027-12-28\",\"notes\":\"Substitute day\",\"bunting\":true}]},\"northern-ireland\":{\"division\":\"northern-ireland\",\"events\":[{\"title\":\"New Year’s Day\",\"date\":\"2018-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2018-03-19\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2018-03-30\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2018-04-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2018-05-07\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2018-05-28\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2018-07-12\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2018-08-27\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2018-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2018-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2019-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2019-03-18\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2019-04-19\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2019-04-22\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2019-05-06\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2019-05-27\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2019-07-12\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2019-08-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2019-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2019-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2020-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2020-03-17\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2020-04-10\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2020-04-13\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday (VE day)\",\"date\":\"2020-05-08\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2020-05-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2020-07-13\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2020-08-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2020-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2020-12-28\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2021-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2021-03-17\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2021-04-02\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2021-04-05\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2021-05-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2021-05-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2021-07-12\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2021-08-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2021-12-27\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2021-12-28\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2022-01-03\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2022-03-17\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2022-04-15\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2022-04-18\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2022-05-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2022-06-02\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Platinum Jubilee bank holiday\",\"date\":\"2022-06-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2022-07-12\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2022-08-29\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Bank Holiday for the State Funeral of Queen Elizabeth II\",\"date\":\"2022-09-19\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Boxing Day\",\"date\":\"2022-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2022-12-27\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2023-01-02\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2023-03-17\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2023-04-07\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2023-04-10\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2023-05-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Bank holiday for the coronation of King Charles III\",\"date\":\"2023-05-08\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2023-05-29\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2023-07-12\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2023-08-28\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2023-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2023-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2024-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2024-03-18\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2024-03-29\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2024-04-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2024-05-06\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2024-05-27\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2024-07-12\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2024-08-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2024-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2024-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2025-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2025-03-17\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2025-04-18\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2025-04-21\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2025-05-05\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2025-05-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2025-07-14\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2025-08-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2025-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2025-12-26\",\"notes\":\"\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2026-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2026-03-17\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2026-04-03\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2026-04-06\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2026-05-04\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2026-05-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2026-07-13\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2026-08-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2026-12-25\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2026-12-28\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"New Year’s Day\",\"date\":\"2027-01-01\",\"notes\":\"\",\"bunting\":true},{\"title\":\"St Patrick’s Day\",\"date\":\"2027-03-17\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Good Friday\",\"date\":\"2027-03-26\",\"notes\":\"\",\"bunting\":false},{\"title\":\"Easter Monday\",\"date\":\"2027-03-29\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Early May bank holiday\",\"date\":\"2027-05-03\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Spring bank holiday\",\"date\":\"2027-05-31\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Battle of the Boyne (Orangemen’s Day)\",\"date\":\"2027-07-12\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Summer bank holiday\",\"date\":\"2027-08-30\",\"notes\":\"\",\"bunting\":true},{\"title\":\"Christmas Day\",\"date\":\"2027-12-27\",\"notes\":\"Substitute day\",\"bunting\":true},{\"title\":\"Boxing Day\",\"date\":\"2027-12-28\",\"notes\":\"Substitute day\",\"bunting\":true}]}}"),
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// this code file caches api responses so refreshing often doesn't use up the quota
+
+const (
+	default_cache_ttl float64       = 30 // seconds
+	cache_file        string        = "cache.json"
+	cache_max_age     time.Duration = 24 * time.Hour // dropped when saving, too old to show
+)
+
+// how a refresh uses the cache
+type cache_mode int
+
+const (
+	cache_normal cache_mode = iota // use entries younger than the ttl
+	cache_force                    // always ask the api, e.g. the refresh button
+	cache_only                     // use entries of any age and never ask the api, e.g. at startup
+)
+
+// what a cache_only request gives when there is nothing saved
+var not_cached = errors.New("board not saved from last time")
+
+type cache_mode_key struct{}
+
+func with_cache_mode(ctx context.Context, mode cache_mode) context.Context {
+	return context.WithValue(ctx, cache_mode_key{}, mode)
+}
+
+func cache_mode_of(ctx context.Context) cache_mode {
+	mode, _ := ctx.Value(cache_mode_key{}).(cache_mode)
+	return mode
+}
+
+type cache_entry struct {
+	Fetched    time.Time         `json:"fetched"`
+	Board      *station_board    `json:"board,omitempty"`
+	Departures *departures_board `json:"departures,omitempty"`
+	Details    *service_details  `json:"details,omitempty"`
+}
+
+// one cache for the life of the app, shared by all refreshes
+var response_cache = struct {
+	mu      sync.Mutex
+	entries map[string]cache_entry
+}{entries: map[string]cache_entry{}}
+
+func cache_get(key string, ttl time.Duration, mode cache_mode) (cache_entry, bool) {
+	response_cache.mu.Lock()
+	defer response_cache.mu.Unlock()
+	entry, ok := response_cache.entries[key]
+	switch {
+	case !ok || mode == cache_force:
+		return cache_entry{}, false
+	case mode == cache_only:
+		return entry, true
+	default:
+		return entry, time.Since(entry.Fetched) < ttl
+	}
+}
+
+func cache_put(key string, entry cache_entry) {
+	response_cache.mu.Lock()
+	defer response_cache.mu.Unlock()
+	response_cache.entries[key] = entry
+}
+
+// read the cache saved last time, a missing or broken file is just an empty cache
+func load_cache(rootURI fyne.URI) {
+	myURI, err := storage.Child(rootURI, cache_file)
+	if err != nil {
+		return
+	}
+	readCloser, err := storage.Reader(myURI)
+	if err != nil {
+		return // not saved yet
+	}
+	defer readCloser.Close()
+	content, err := io.ReadAll(readCloser)
+	if err != nil {
+		return
+	}
+
+	entries := map[string]cache_entry{}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		log.Printf("ignoring broken %s: %v", cache_file, err)
+		return
+	}
+	response_cache.mu.Lock()
+	defer response_cache.mu.Unlock()
+	for key, entry := range entries {
+		response_cache.entries[key] = entry
+	}
+}
+
+func save_cache(rootURI fyne.URI) error {
+	response_cache.mu.Lock()
+	defer response_cache.mu.Unlock()
+	boards := map[string]cache_entry{}
+	for key, entry := range response_cache.entries {
+		if time.Since(entry.Fetched) > cache_max_age {
+			delete(response_cache.entries, key)
+		} else if entry.Board != nil || entry.Departures != nil {
+			boards[key] = entry
+		}
+	}
+	return save_json(boards, cache_file, rootURI)
+}
+
+// DepartureSource that remembers what another source returned
+type cached_source struct {
+	inner   DepartureSource
+	name    string // which source, so switching source doesn't show old data
+	ttl     time.Duration
+	rootURI fyne.URI // where to save, nil for memory only
+}
+
+func new_cached_source(inner DepartureSource, name string, s settings, rootURI fyne.URI) cached_source {
+	ttl := s.Cache_ttl
+	if ttl < 0 {
+		ttl = 0
+	}
+	return cached_source{inner: inner, name: name, ttl: time.Duration(ttl * float64(time.Second)), rootURI: rootURI}
+}
+
+// endpoint, crs and filter parameters
+func (src cached_source) board_key(endpoint string, q board_query) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%d|%s|%d|%d", src.name, endpoint, q.crs, q.filter_crs, q.filter_type, q.num_rows,
+		strings.Join(q.filter_list, ","), q.time_offset, q.time_window)
+}
+
+func (src cached_source) board(ctx context.Context, endpoint string, q board_query,
+	get func(context.Context, board_query) (station_board, error)) (station_board, error) {
+	key := src.board_key(endpoint, q)
+	if entry, ok := cache_get(key, src.ttl, cache_mode_of(ctx)); ok && entry.Board != nil {
+		sb := *entry.Board
+		sb.fetched = entry.Fetched
+		return sb, nil
+	} else if cache_mode_of(ctx) == cache_only {
+		return station_board{}, not_cached
+	}
+	sb, err := get(ctx, q)
+	if err != nil {
+		return sb, err
+	}
+	sb.fetched = time.Now()
+	cache_put(key, cache_entry{Fetched: sb.fetched, Board: &sb})
+	if src.rootURI != nil {
+		if err := save_cache(src.rootURI); err != nil {
+			log.Printf("cannot save %s: %v", cache_file, err)
+		}
+	}
+	return sb, nil
+}
+
+func (src cached_source) Departures(ctx context.Context, q board_query) (station_board, error) {
+	return src.board(ctx, "dep", q, src.inner.Departures)
+}
+
+func (src cached_source) Arrivals(ctx context.Context, q board_query) (station_board, error) {
+	return src.board(ctx, "arr", q, src.inner.Arrivals)
+}
+
+func (src cached_source) departures(ctx context.Context, endpoint string, q board_query,
+	get func(context.Context, board_query) (departures_board, error)) (departures_board, error) {
+	key := src.board_key(endpoint, q)
+	if entry, ok := cache_get(key, src.ttl, cache_mode_of(ctx)); ok && entry.Departures != nil {
+		db := *entry.Departures
+		db.fetched = entry.Fetched
+		return db, nil
+	} else if cache_mode_of(ctx) == cache_only {
+		return departures_board{}, not_cached
+	}
+	db, err := get(ctx, q)
+	if err != nil {
+		return db, err
+	}
+	db.fetched = time.Now()
+	cache_put(key, cache_entry{Fetched: db.fetched, Departures: &db})
+	if src.rootURI != nil {
+		if err := save_cache(src.rootURI); err != nil {
+			log.Printf("cannot save %s: %v", cache_file, err)
+		}
+	}
+	return db, nil
+}
+
+func (src cached_source) NextDepartures(ctx context.Context, q board_query) (departures_board, error) {
+	return src.departures(ctx, "next", q, src.inner.NextDepartures)
+}
+
+func (src cached_source) FastestDepartures(ctx context.Context, q board_query) (departures_board, error) {
+	return src.departures(ctx, "fastest", q, src.inner.FastestDepartures)
+}
+
+// details are only kept in memory, they are not needed at startup
+func (src cached_source) ServiceDetails(ctx context.Context, service_id string) (service_details, error) {
+	key := fmt.Sprintf("%s|svc|%s", src.name, service_id)
+	if entry, ok := cache_get(key, src.ttl, cache_mode_of(ctx)); ok && entry.Details != nil {
+		return *entry.Details, nil
+	} else if cache_mode_of(ctx) == cache_only {
+		return service_details{}, not_cached
+	}
+	details, err := src.inner.ServiceDetails(ctx, service_id)
+	if err != nil {
+		return details, err
+	}
+	cache_put(key, cache_entry{Fetched: time.Now(), Details: &details})
+	return details, nil
+}
+
+// how old a board is if it was not just fetched, e.g. ", saved 08:02 yesterday"
+// boards shown at startup can be from the day before and look just like live ones
+func saved_text(fetched, now time.Time) string {
+	if fetched.IsZero() || now.Sub(fetched) < time.Minute {
+		return ""
+	}
+	fetched, now = fetched.In(london), now.In(london)
+	text := ", saved " + fetched.Format("15:04")
+	switch fetched.Format(date_format) {
+	case now.Format(date_format):
+	case now.AddDate(0, 0, -1).Format(date_format):
+		text += " yesterday"
+	default:
+		text += fetched.Format(" on Mon 2 Jan")
+	}
+	return text
+}
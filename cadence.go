@@ -0,0 +1,154 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// this code file decides when the home tab refreshes next
+// Freq is the usual gap, shorter when a train is about to go, longer when nobody is looking
+
+const (
+	default_min_freq float64 = 15  // seconds
+	default_max_freq float64 = 600 // seconds
+
+	imminent_departure time.Duration = 5 * time.Minute // poll at Min_freq when a train goes this soon
+	unfocused_factor   float64       = 4               // slower when the window is in the background
+	idle_recheck       time.Duration = time.Hour       // longest sleep outside every window
+)
+
+var cadence = struct {
+	mu      sync.Mutex
+	last    time.Time // last refresh, from anywhere
+	soonest int       // minutes to the first train shown, -1 for none
+	focused bool
+	wake    chan struct{}
+}{soonest: -1, focused: true, wake: make(chan struct{}, 1)}
+
+// a refresh is starting, the next one is timed from now
+func note_attempt() {
+	cadence.mu.Lock()
+	defer cadence.mu.Unlock()
+	cadence.last = time.Now()
+}
+
+// what a refresh put on the home tab
+func note_boards(boards []board) {
+	cadence.mu.Lock()
+	defer cadence.mu.Unlock()
+	cadence.soonest = soonest_departure(boards, uk_now())
+}
+
+func set_focused(focused bool) {
+	cadence.mu.Lock()
+	cadence.focused = focused
+	cadence.mu.Unlock()
+	wake_scheduler()
+}
+
+// work out the next refresh again, e.g. when quick times change
+func wake_scheduler() {
+	select {
+	case cadence.wake <- struct{}{}:
+	default: // already woken
+	}
+}
+
+// minutes to the first train on the boards, expected time if known, -1 for none
+func soonest_departure(boards []board, now time.Time) int {
+	now_mins := now.Hour()*60 + now.Minute()
+	soonest := -1
+	for _, b := range boards {
+		for _, ts := range b.services {
+			if ts.cancelled {
+				continue
+			}
+			mins, ok := hhmm_minutes(first_of(ts.etd, ts.eta))
+			if !ok { // "On time", "Delayed" and so on
+				mins, ok = hhmm_minutes(first_of(ts.std, ts.sta))
+			}
+			if !ok {
+				continue
+			}
+			until := (mins - now_mins + 24*60) % (24 * 60)
+			if until > 12*60 {
+				continue // already gone
+			}
+			if soonest < 0 || until < soonest {
+				soonest = until
+			}
+		}
+	}
+	return soonest
+}
+
+// seconds in settings
+func seconds(secs float64) time.Duration {
+	return time.Duration(secs * float64(time.Second))
+}
+
+// when the home tab should refresh next, last is the refresh before
+func next_refresh(s settings, all []quick_time, last, now time.Time) time.Time {
+	cadence.mu.Lock()
+	soonest, focused := cadence.soonest, cadence.focused
+	cadence.mu.Unlock()
+
+	if !any_window_open(all, now) {
+		// nothing to update until a window opens
+		next := last.Add(idle_recheck)
+		if start, ok := next_window_start(all, now); ok && start.Before(next) {
+			next = start.Add(time.Second)
+		}
+		return next
+	}
+
+	min_freq, max_freq := s.Min_freq, s.Max_freq
+	if min_freq <= 0 {
+		min_freq = default_min_freq
+	}
+	if max_freq < min_freq {
+		max_freq = max(min_freq, default_max_freq)
+	}
+	interval := max(s.Freq, min_freq)
+	if soonest >= 0 && time.Duration(soonest)*time.Minute <= imminent_departure {
+		interval = min_freq
+	}
+	if !focused {
+		interval *= unfocused_factor
+	}
+	next := last.Add(seconds(min(interval, max_freq)))
+
+	// boards come and go with their windows, not with the interval
+	for _, change := range []func([]quick_time, time.Time) (time.Time, bool){next_window_end, next_window_start} {
+		if at, ok := change(all, now); ok && at.Add(time.Second).Before(next) {
+			next = at.Add(time.Second)
+		}
+	}
+	return next
+}
+
+// refresh the home tab whenever it is due, for the life of the app
+func run_scheduler(s settings, rootURI fyne.URI, refresh func()) {
+	for {
+		_, saved, _ := load_json("qtt.json", rootURI) // a broken file shows when refreshing
+		now := time.Now()
+		cadence.mu.Lock()
+		last := cadence.last
+		cadence.mu.Unlock()
+
+		wait := next_refresh(s, saved.Quick_times, last, now).Sub(now)
+		if wait <= 0 {
+			note_attempt() // in case the refresh gives up early
+			refresh()
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-cadence.wake:
+			timer.Stop()
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// this code file handles the http side of the api, shared by all sources
+
+const (
+	max_attempts    int           = 4 // first try and three retries
+	base_backoff    time.Duration = 500 * time.Millisecond
+	max_backoff     time.Duration = 8 * time.Second
+	max_retry_after time.Duration = 30 * time.Second // longer waits are not worth it for a board
+	default_timeout float64       = 10               // seconds
+)
+
+// one client for the life of the app, so connections are reused
+var shared_client struct {
+	once   sync.Once
+	client *http.Client
+}
+
+func api_client(s settings) *http.Client {
+	shared_client.once.Do(func() {
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = default_timeout
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConnsPerHost = 4
+		shared_client.client = &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(timeout * float64(time.Second)),
+		}
+	})
+	return shared_client.client
+}
+
+// which responses are worth another go, e.g. not soap faults
+type retry_check func(status int, body []byte) bool
+
+func retry_server_errors(status int, body []byte) bool {
+	return status >= 500
+}
+
+// send a request, retrying network errors and the responses retryable allows
+// with exponential backoff, rate limits wait for Retry-After instead
+// build is called for every attempt since a request body can only be read once
+func send(ctx context.Context, client *http.Client, build func() (*http.Request, error), retryable retry_check) (int, []byte, error) {
+	var status int
+	var body []byte
+	var err error
+	for attempt := range max_attempts {
+		var wait time.Duration
+		status, body, wait, err = send_once(ctx, client, build)
+		if ctx.Err() != nil {
+			return 0, nil, ctx.Err() // cancelled, don't bother
+		}
+
+		if err == nil && status != http.StatusTooManyRequests && !retryable(status, body) {
+			return status, body, nil // done, good or bad
+		}
+		if attempt == max_attempts-1 {
+			break
+		}
+
+		if status == http.StatusTooManyRequests {
+			if wait > max_retry_after {
+				break // told to come back much later
+			}
+		} else {
+			wait = backoff(attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return status, body, err
+}
+
+// one attempt, with how long the server asked to wait if it did
+func send_once(ctx context.Context, client *http.Client, build func() (*http.Request, error)) (int, []byte, time.Duration, error) {
+	req, err := build()
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	return res.StatusCode, body, retry_after(res.Header.Get("Retry-After")), nil
+}
+
+// 500ms, 1s, 2s... with some jitter so clients don't retry in step
+func backoff(attempt int) time.Duration {
+	wait := min(base_backoff<<attempt, max_backoff)
+	return wait/2 + rand.N(wait/2)
+}
+
+// Retry-After is either seconds or an http date
+func retry_after(header string) time.Duration {
+	if header == "" {
+		return base_backoff
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		return max(time.Until(when), 0)
+	}
+	return base_backoff
+}
+
+// ----- refresh cancellation -----
+
+// only one refresh runs at a time, starting a new one cancels the old one
+var refresh_state struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func start_refresh() context.Context {
+	refresh_state.mu.Lock()
+	defer refresh_state.mu.Unlock()
+	if refresh_state.cancel != nil {
+		refresh_state.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	refresh_state.cancel = cancel
+	return ctx
+}
+
+func cancel_refresh() {
+	refresh_state.mu.Lock()
+	defer refresh_state.mu.Unlock()
+	if refresh_state.cancel != nil {
+		refresh_state.cancel()
+		refresh_state.cancel = nil
+	}
+}
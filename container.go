@@ -4,44 +4,39 @@ import (
 	"fyne.io/fyne/v2"
 )
 
-// HalfHeightLayout is a custom layout that arranges two objects
-// vertically, each taking half the available height and full width.
-type HalfHeightLayout struct{}
+// SplitHeightLayout is a custom layout that arranges any number of objects
+// vertically, each taking an equal share of the available height and full width.
+type SplitHeightLayout struct{}
 
-// NewHalfHeightLayout creates a new instance of HalfHeightLayout.
-func NewHalfHeightLayout() fyne.Layout {
-	return &HalfHeightLayout{}
+// NewSplitHeightLayout creates a new instance of SplitHeightLayout.
+func NewSplitHeightLayout() fyne.Layout {
+	return &SplitHeightLayout{}
 }
 
 // Layout arranges the objects within the container.
-func (h *HalfHeightLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
-	if len(objects) != 2 {
-		// Log an error or handle the case where there aren't exactly two objects
-		// For simplicity, we'll just return here.
+func (h *SplitHeightLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	if len(objects) == 0 {
 		return
 	}
 
-	halfHeight := size.Height / 2
+	paneHeight := size.Height / float32(len(objects))
 
-	// Position and size the first object
-	objects[0].Resize(fyne.NewSize(size.Width, halfHeight))
-	objects[0].Move(fyne.NewPos(0, 0))
-
-	// Position and size the second object
-	objects[1].Resize(fyne.NewSize(size.Width, halfHeight))
-	objects[1].Move(fyne.NewPos(0, halfHeight))
+	// Position and size each object below the one before it
+	for i, obj := range objects {
+		obj.Resize(fyne.NewSize(size.Width, paneHeight))
+		obj.Move(fyne.NewPos(0, paneHeight*float32(i)))
+	}
 }
 
 // MinSize calculates the minimum size required for the layout.
-func (h *HalfHeightLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
-	if len(objects) != 2 {
-		return fyne.NewSize(0, 0) // Or a sensible default
+func (h *SplitHeightLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	// The minimum width is the maximum of the objects' minimum widths.
+	// The minimum height is the sum of the objects' minimum heights.
+	var minWidth, minHeight float32
+	for _, obj := range objects {
+		minWidth = fyne.Max(minWidth, obj.MinSize().Width)
+		minHeight += obj.MinSize().Height
 	}
 
-	// The minimum width is the maximum of the two objects' minimum widths.
-	// The minimum height is the sum of the two objects' minimum heights.
-	minWidth := fyne.Max(objects[0].MinSize().Width, objects[1].MinSize().Width)
-	minHeight := objects[0].MinSize().Height + objects[1].MinSize().Height
-
 	return fyne.NewSize(minWidth, minHeight)
 }
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// this code file handles the legacy Darwin OpenLDBWS SOAP api
+// https://lite.realtime.nationalrail.co.uk/OpenLDBWS/
+
+const (
+	darwin_url       string = "https://lite.realtime.nationalrail.co.uk/OpenLDBWS/ldb12.asmx"
+	darwin_ldb_ns    string = "http://thalesgroup.com/RTTI/2021-11-01/ldb/"
+	darwin_token_ns  string = "http://thalesgroup.com/RTTI/2013-11-28/Token/types"
+	darwin_action_ns string = "http://thalesgroup.com/RTTI/2012-01-13/ldb/" // SOAPAction keeps the old namespace
+	darwin_product   string = "Darwin OpenLDBWS"
+)
+
+// Darwin uses one token for everything
+type darwin_source struct {
+	token  string
+	client *http.Client
+}
+
+// what comes back in the soap body, only one of the results is set
+type soap_envelope struct {
+	Body struct {
+		Fault    *soap_fault `xml:"Fault"`
+		Response struct {
+			Board      *station_board    `xml:"GetStationBoardResult"`
+			Departures *departures_board `xml:"DeparturesBoard"`
+			Details    *service_details  `xml:"GetServiceDetailsResult"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+type soap_fault struct {
+	Code   string `xml:"faultcode"`
+	String string `xml:"faultstring"`
+}
+
+// one request parameter, order matters to the server
+type soap_param struct {
+	name  string
+	value string
+}
+
+// parameters holding a comma separated list, sent as one element per item
+var soap_list_items = map[string]string{
+	"filterList": "crs",
+}
+
+// post a soap request for an operation, return the decoded envelope
+// crs is the station asked for, only used to explain errors
+func (src darwin_source) call(ctx context.Context, operation, crs string, params []soap_param) (soap_envelope, error) {
+	if src.token == "" {
+		return soap_envelope{}, &key_error{product: darwin_product, missing: true}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	buf.WriteString(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"`)
+	fmt.Fprintf(&buf, ` xmlns:typ="%s" xmlns:ldb="%s">`, darwin_token_ns, darwin_ldb_ns)
+	buf.WriteString(`<soap:Header><typ:AccessToken><typ:TokenValue>`)
+	xml.EscapeText(&buf, []byte(src.token))
+	buf.WriteString(`</typ:TokenValue></typ:AccessToken></soap:Header>`)
+	fmt.Fprintf(&buf, `<soap:Body><ldb:%sRequest>`, operation)
+	for _, p := range params {
+		fmt.Fprintf(&buf, `<ldb:%s>`, p.name)
+		if item, ok := soap_list_items[p.name]; ok {
+			for _, v := range strings.Split(p.value, ",") {
+				fmt.Fprintf(&buf, `<ldb:%s>`, item)
+				xml.EscapeText(&buf, []byte(v))
+				fmt.Fprintf(&buf, `</ldb:%s>`, item)
+			}
+		} else {
+			xml.EscapeText(&buf, []byte(p.value))
+		}
+		fmt.Fprintf(&buf, `</ldb:%s>`, p.name)
+	}
+	fmt.Fprintf(&buf, `</ldb:%sRequest></soap:Body></soap:Envelope>`, operation)
+	payload := buf.Bytes()
+
+	build := func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", darwin_url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		req.Header.Set("SOAPAction", darwin_action_ns+operation)
+		return req, nil
+	}
+	// faults also come with a 500, but asking again won't change the answer
+	retryable := func(status int, body []byte) bool {
+		return status >= 500 && !bytes.Contains(body, []byte("Fault>"))
+	}
+
+	status, body, err := send(ctx, src.client, build, retryable)
+	if ctx.Err() != nil {
+		return soap_envelope{}, ctx.Err()
+	} else if err != nil {
+		return soap_envelope{}, &offline_error{err: err}
+	}
+
+	var env soap_envelope
+	xml_err := xml.Unmarshal(body, &env)
+	if xml_err == nil && env.Body.Fault != nil {
+		// the text is more useful than the code
+		fault := strings.TrimSpace(env.Body.Fault.String)
+		if crs != "" && strings.Contains(strings.ToLower(fault), "invalid crs") {
+			return soap_envelope{}, &unknown_crs_error{crs: crs}
+		}
+		return soap_envelope{}, fmt.Errorf("Darwin error: %s", fault)
+	}
+	if status != 200 {
+		return soap_envelope{}, classify_status(status, body, darwin_product, crs)
+	}
+	if xml_err != nil {
+		return soap_envelope{}, fmt.Errorf("cannot read Darwin response: %w", xml_err)
+	}
+	return env, nil
+}
+
+func (src darwin_source) board(ctx context.Context, operation string, q board_query) (station_board, error) {
+	params := []soap_param{{"numRows", fmt.Sprint(q.num_rows)}, {"crs", q.crs}}
+	if q.filter_crs != "*" {
+		params = append(params, soap_param{"filterCrs", q.filter_crs}, soap_param{"filterType", q.filter_type})
+	}
+	time_names, time_vals := q.time_params()
+	for i, name := range time_names {
+		params = append(params, soap_param{name, time_vals[i]})
+	}
+
+	crs := q.crs
+	if q.filter_crs != "*" {
+		crs += " or " + q.filter_crs // can't tell which one
+	}
+	env, err := src.call(ctx, operation, crs, params)
+	if err != nil {
+		return station_board{}, err
+	}
+	if env.Body.Response.Board == nil {
+		return station_board{}, errors.New("Darwin response has no board")
+	}
+
+	board := *env.Body.Response.Board
+	board.TrainServices = valid_services(board.TrainServices, "train", board.Crs)
+	board.BusServices = valid_services(board.BusServices, "bus", board.Crs)
+	board.FerryServices = valid_services(board.FerryServices, "ferry", board.Crs)
+	return board, nil
+}
+
+func (src darwin_source) Departures(ctx context.Context, q board_query) (station_board, error) {
+	return src.board(ctx, "GetDepBoardWithDetails", q)
+}
+
+func (src darwin_source) Arrivals(ctx context.Context, q board_query) (station_board, error) {
+	return src.board(ctx, "GetArrBoardWithDetails", q)
+}
+
+func (src darwin_source) departures(ctx context.Context, operation string, q board_query) (departures_board, error) {
+	params := []soap_param{{"crs", q.crs}, {"filterList", strings.Join(q.filter_list, ",")}}
+	time_names, time_vals := q.time_params()
+	for i, name := range time_names {
+		params = append(params, soap_param{name, time_vals[i]})
+	}
+
+	env, err := src.call(ctx, operation, strings.Join(append([]string{q.crs}, q.filter_list...), ", "), params)
+	if err != nil {
+		return departures_board{}, err
+	}
+	if env.Body.Response.Departures == nil {
+		return departures_board{}, errors.New("Darwin response has no departures")
+	}
+
+	board := *env.Body.Response.Departures
+	board.Departures = valid_departures(board.Departures, board.Crs)
+	return board, nil
+}
+
+func (src darwin_source) NextDepartures(ctx context.Context, q board_query) (departures_board, error) {
+	return src.departures(ctx, "GetNextDeparturesWithDetails", q)
+}
+
+func (src darwin_source) FastestDepartures(ctx context.Context, q board_query) (departures_board, error) {
+	return src.departures(ctx, "GetFastestDeparturesWithDetails", q)
+}
+
+func (src darwin_source) ServiceDetails(ctx context.Context, service_id string) (service_details, error) {
+	if service_id == "" {
+		return service_details{}, errors.New("this service has no service ID")
+	}
+	env, err := src.call(ctx, "GetServiceDetails", "", []soap_param{{"serviceID", service_id}})
+	if err != nil {
+		return service_details{}, err
+	}
+	if env.Body.Response.Details == nil {
+		return service_details{}, errors.New("Darwin response has no service details")
+	}
+	return *env.Body.Response.Details, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// this code file handles the service details view
+
+var details_col_headers = []string{"CRS", "Sch", "Est", "Act", "Plat", "Canc"}
+
+// first non-empty string
+func first_of(strs ...string) string {
+	for _, s := range strs {
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func cancelled_text(cancelled bool) string {
+	if cancelled {
+		return "Yes"
+	}
+	return ""
+}
+
+// rows of the calling points table and their row headers
+// < before this station, * this station, > after this station
+func details_rows(d service_details) ([][]string, []string) {
+	var data [][]string
+	var row_headers []string
+
+	add_points := func(sets []calling_point_set, marker string) {
+		for _, set := range sets {
+			for _, cp := range set.CallingPoint {
+				data = append(data, []string{cp.Crs, cp.St, cp.Et, cp.At, "", cancelled_text(cp.IsCancelled)})
+				row_headers = append(row_headers, marker)
+			}
+		}
+	}
+
+	add_points(d.PreviousCallingPoints, "<")
+	data = append(data, []string{d.Crs,
+		first_of(d.Std, d.Sta),
+		first_of(d.Etd, d.Eta),
+		first_of(d.Atd, d.Ata),
+		first_of(d.Platform, "?"),
+		cancelled_text(d.IsCancelled)})
+	row_headers = append(row_headers, "*")
+	add_points(d.SubsequentCallingPoints, ">")
+
+	return data, row_headers
+}
+
+// explain why a service is cancelled or late
+func show_reasons(ts train_service, mywin_addr *fyne.Window) {
+	if !ts.has_info() {
+		return
+	}
+	var lines []string
+	if ts.cancel_reason != "" {
+		lines = append(lines, ts.cancel_reason)
+	} else if ts.cancelled {
+		lines = append(lines, "This service is cancelled, no reason given.")
+	}
+	if ts.delay_reason != "" {
+		lines = append(lines, ts.delay_reason)
+	}
+	lines = append(lines, ts.alerts...)
+
+	label := widget.NewLabel(strings.Join(lines, "\n\n"))
+	label.Wrapping = fyne.TextWrapWord
+	reasons_dialog := dialog.NewCustom(fmt.Sprintf("%s %s", first_of(ts.std, ts.sta), ts.operator), "Close", label, *mywin_addr)
+	reasons_dialog.Resize(fyne.NewSize(400, 240))
+	reasons_dialog.Show()
+}
+
+// how full each coach is, for operators that report it
+func show_loading(ts train_service, mywin_addr *fyne.Window) {
+	lines := []string{fmt.Sprintf("%s on average, %d%% full", loading_category(ts.loading), ts.loading)}
+	for _, c := range ts.coaches {
+		line := fmt.Sprintf("Coach %s", first_of(c.Number, "?"))
+		if c.CoachClass != "" {
+			line += " (" + c.CoachClass + ")"
+		}
+		if c.LoadingSpecified {
+			line += fmt.Sprintf(": %s, %d%%", loading_category(c.Loading), c.Loading)
+		}
+		lines = append(lines, line)
+	}
+	dialog.ShowInformation("Loading", strings.Join(lines, "\n"), *mywin_addr)
+}
+
+// full names of where a service goes, for destinations that are not a single crs
+func show_destinations(ts train_service, mywin_addr *fyne.Window) {
+	var lines []string
+	for _, loc := range ts.dests {
+		name := loc.LocationName
+		if name == "" {
+			name, _ = crs_to_name(loc.Crs)
+		}
+		line := fmt.Sprintf("%s (%s)", first_of(name, loc.Crs), loc.Crs)
+		if loc.Via != "" {
+			line += " " + loc.Via
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return
+	}
+	title := "Destination"
+	if len(lines) > 1 {
+		title = "Destinations, the train divides"
+	}
+	dialog.ShowInformation(title, strings.Join(lines, "\n"), *mywin_addr)
+}
+
+// fetch details of a service and show them in a dialog
+// called in a goroutine, the ui is only touched in fyne.Do
+func show_service_details(ts train_service, src DepartureSource, mywin_addr *fyne.Window) {
+	mywin_obj := *mywin_addr
+	details, err := src.ServiceDetails(context.Background(), ts.service_id)
+	if err != nil {
+		fyne.Do(func() { dialog.ShowError(errors.New(error_text(err)), mywin_obj) })
+		return
+	}
+
+	data, row_headers := details_rows(details)
+	title := fmt.Sprintf("%s %s service at %s", first_of(details.Std, details.Sta), details.Operator, details.LocationName)
+
+	fyne.Do(func() {
+		config := NewTableConfig(data, details_col_headers, row_headers)
+		config.CellTemplateText = "?"
+		table := config.BuildTable(mywin_addr)
+		table.SetColumnWidth(-1, 30)
+		table.SetColumnWidth(0, 50) // crs
+		table.SetColumnWidth(1, 60) // sch
+		table.SetColumnWidth(2, 80) // est
+		table.SetColumnWidth(3, 80) // act
+		table.SetColumnWidth(4, 40) // plat
+		table.SetColumnWidth(5, 50) // canc
+
+		content := container.NewBorder(widget.NewLabel(title), nil, nil, nil, table)
+		details_dialog := dialog.NewCustom("Service Details", "Close", content, mywin_obj)
+		details_dialog.Resize(fyne.NewSize(480, 480))
+		details_dialog.Show()
+	})
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// this code file classifies api errors so the ui can say what to do about them
+
+// errors the user can do something about
+type user_error interface {
+	error
+	action() string // what to do next, shown under the message
+}
+
+// key missing, wrong or expired
+type key_error struct {
+	product string
+	missing bool
+}
+
+func (e *key_error) Error() string {
+	if e.missing {
+		return fmt.Sprintf("no API key for %s", e.product)
+	}
+	return fmt.Sprintf("the API key for %s was refused, it may be wrong or expired", e.product)
+}
+
+func (e *key_error) action() string {
+	return "Open Settings to update your key."
+}
+
+// key is fine but not for this product
+type unsubscribed_error struct {
+	product string
+}
+
+func (e *unsubscribed_error) Error() string {
+	return fmt.Sprintf("your key is not subscribed to %s", e.product)
+}
+
+func (e *unsubscribed_error) action() string {
+	return "Subscribe to it on Rail Data Marketplace, then put its key in Settings."
+}
+
+type rate_limit_error struct{}
+
+func (e *rate_limit_error) Error() string {
+	return "too many requests, the API is limiting this key"
+}
+
+func (e *rate_limit_error) action() string {
+	return "Wait a minute, or refresh less often in Settings."
+}
+
+type unknown_crs_error struct {
+	crs string
+}
+
+func (e *unknown_crs_error) Error() string {
+	return fmt.Sprintf("station code %s is not recognised by the API", e.crs)
+}
+
+func (e *unknown_crs_error) action() string {
+	return "Check the station codes of your entries in Config QTTs."
+}
+
+// server side problems
+type outage_error struct {
+	status int
+}
+
+func (e *outage_error) Error() string {
+	return fmt.Sprintf("the train times service is having problems (HTTP %d)", e.status)
+}
+
+func (e *outage_error) action() string {
+	return "Try again later, this is not a problem with the app."
+}
+
+// no network, dns, timeouts
+type offline_error struct {
+	err error
+}
+
+func (e *offline_error) Error() string {
+	return fmt.Sprintf("cannot reach the train times service: %v", e.err)
+}
+
+func (e *offline_error) Unwrap() error {
+	return e.err
+}
+
+func (e *offline_error) action() string {
+	return "Check your internet connection."
+}
+
+// message with the suggested action if there is one
+func error_text(err error) string {
+	var u_err user_error
+	if errors.As(err, &u_err) {
+		return err.Error() + ". " + u_err.action()
+	}
+	return err.Error()
+}
+
+// turn a failed response into one of the errors above where possible
+// crs is the station asked for, empty if not asking for a board
+func classify_status(status int, body []byte, product, crs string) error {
+	switch {
+	case status == http.StatusUnauthorized:
+		return &key_error{product: product}
+	case status == http.StatusForbidden:
+		if bytes.Contains(bytes.ToLower(body), []byte("subscri")) {
+			return &unsubscribed_error{product: product}
+		}
+		return &key_error{product: product}
+	case status == http.StatusTooManyRequests:
+		return &rate_limit_error{}
+	case crs != "" && (status == http.StatusBadRequest || status == http.StatusNotFound ||
+		bytes.Contains(bytes.ToLower(body), []byte("invalid crs"))):
+		return &unknown_crs_error{crs: crs}
+	case status >= 500:
+		return &outage_error{status: status}
+	case status > 299:
+		return fmt.Errorf("HTTP Error Code %d", status)
+	default:
+		return fmt.Errorf("HTTP status code %d", status)
+	}
+}
+
+// tell the user about a failed refresh
+// problems that fix themselves only go in the label, the others get a dialog
+// with a button to where they can be fixed
+func show_api_error(err error, mywin_obj fyne.Window, apptabs_obj *container.AppTabs, mylabel_obj *widget.Label) {
+	var key_err *key_error
+	var unsub_err *unsubscribed_error
+	var crs_err *unknown_crs_error
+	var offline_err *offline_error
+	var outage_err *outage_error
+	var rate_err *rate_limit_error
+
+	// button text and tab to open
+	go_to := func(button string, tab int) {
+		msg := widget.NewLabel(error_text(err))
+		msg.Wrapping = fyne.TextWrapWord
+		dialog.ShowCustomConfirm("Cannot get train times", button, "Close", msg, func(ok bool) {
+			if ok {
+				apptabs_obj.SelectIndex(tab)
+			}
+		}, mywin_obj)
+	}
+
+	fyne.Do(func() {
+		switch {
+		case errors.As(err, &key_err), errors.As(err, &unsub_err):
+			go_to("Open Settings", 1)
+		case errors.As(err, &crs_err):
+			go_to("Open Config QTTs", 2)
+		case errors.As(err, &offline_err), errors.As(err, &outage_err), errors.As(err, &rate_err):
+			mylabel_obj.SetText(error_text(err))
+		default:
+			mylabel_obj.SetText("")
+			dialog.ShowError(err, mywin_obj)
+		}
+	})
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// this code file models the LDBWS (live departure board web service) json
+// the xml tags are for the SOAP version of the same data
+
+// whole station board, as returned by GetDepartureBoard and friends
+type station_board struct {
+	GeneratedAt          string         `json:"generatedAt" xml:"generatedAt"`
+	LocationName         string         `json:"locationName" xml:"locationName"`
+	Crs                  string         `json:"crs" xml:"crs"`
+	FilterLocationName   string         `json:"filterLocationName" xml:"filterLocationName"`
+	FilterCrs            string         `json:"filtercrs" xml:"filtercrs"`
+	FilterType           string         `json:"filterType" xml:"filterType"`
+	StationManager       string         `json:"stationManager" xml:"stationManager"`
+	StationManagerCode   string         `json:"stationManagerCode" xml:"stationManagerCode"`
+	NrccMessages         []nrcc_message `json:"nrccMessages" xml:"nrccMessages>message"`
+	PlatformAvailable    bool           `json:"platformAvailable" xml:"platformAvailable"`
+	AreServicesAvailable bool           `json:"areServicesAvailable" xml:"areServicesAvailable"`
+	TrainServices        []service_item `json:"trainServices" xml:"trainServices>service"`
+	BusServices          []service_item `json:"busServices" xml:"busServices>service"`
+	FerryServices        []service_item `json:"ferryServices" xml:"ferryServices>service"`
+
+	fetched time.Time // when it came from the api, set by cached_source
+}
+
+// next or fastest service to each of several destinations,
+// as returned by GetNextDepartures and GetFastestDepartures
+type departures_board struct {
+	GeneratedAt          string           `json:"generatedAt" xml:"generatedAt"`
+	LocationName         string           `json:"locationName" xml:"locationName"`
+	Crs                  string           `json:"crs" xml:"crs"`
+	NrccMessages         []nrcc_message   `json:"nrccMessages" xml:"nrccMessages>message"`
+	PlatformAvailable    bool             `json:"platformAvailable" xml:"platformAvailable"`
+	AreServicesAvailable bool             `json:"areServicesAvailable" xml:"areServicesAvailable"`
+	Departures           []departure_item `json:"departures" xml:"departures>destination"`
+
+	fetched time.Time // when it came from the api, set by cached_source
+}
+
+// one destination, the service is missing if nothing goes there soon
+type departure_item struct {
+	Crs     string        `json:"crs" xml:"crs,attr"`
+	Service *service_item `json:"service" xml:"service"`
+}
+
+// station disruption message, may contain html
+type nrcc_message struct {
+	Value string `json:"Value" xml:",chardata"`
+}
+
+var html_tag = regexp.MustCompile(`<[^>]*>`)
+var html_break = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</li>`)
+var blank_lines = regexp.MustCompile(`\n\s*\n+`)
+
+// plain text of a snippet of html, as used in messages and alerts
+func html_to_text(s string) string {
+	s = html_break.ReplaceAllString(s, "\n")
+	s = html_tag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = blank_lines.ReplaceAllString(s, "\n")
+	return strings.TrimSpace(s)
+}
+
+func (m nrcc_message) text() string {
+	return html_to_text(m.Value)
+}
+
+// one service on a board
+type service_item struct {
+	Origin                  []service_location  `json:"origin" xml:"origin>location"`
+	Destination             []service_location  `json:"destination" xml:"destination>location"`
+	CurrentOrigins          []service_location  `json:"currentOrigins" xml:"currentOrigins>location"`
+	CurrentDestinations     []service_location  `json:"currentDestinations" xml:"currentDestinations>location"`
+	Rsid                    string              `json:"rsid" xml:"rsid"`
+	Sta                     string              `json:"sta" xml:"sta"`
+	Eta                     string              `json:"eta" xml:"eta"`
+	Std                     string              `json:"std" xml:"std"`
+	Etd                     string              `json:"etd" xml:"etd"`
+	Platform                string              `json:"platform" xml:"platform"`
+	Operator                string              `json:"operator" xml:"operator"`
+	OperatorCode            string              `json:"operatorCode" xml:"operatorCode"`
+	IsCircularRoute         bool                `json:"isCircularRoute" xml:"isCircularRoute"`
+	IsCancelled             bool                `json:"isCancelled" xml:"isCancelled"`
+	FilterLocationCancelled bool                `json:"filterLocationCancelled" xml:"filterLocationCancelled"`
+	ServiceType             string              `json:"serviceType" xml:"serviceType"`
+	Length                  int                 `json:"length" xml:"length"`
+	DetachFront             bool                `json:"detachFront" xml:"detachFront"`
+	IsReverseFormation      bool                `json:"isReverseFormation" xml:"isReverseFormation"`
+	CancelReason            reason              `json:"cancelReason" xml:"cancelReason"`
+	DelayReason             reason              `json:"delayReason" xml:"delayReason"`
+	ServiceID               string              `json:"serviceID" xml:"serviceID"`
+	AdhocAlerts             []string            `json:"adhocAlerts" xml:"adhocAlerts>adhocAlertText"`
+	Formation               *formation          `json:"formation" xml:"formation"`
+	PreviousCallingPoints   []calling_point_set `json:"previousCallingPoints" xml:"previousCallingPoints>callingPointList"`
+	SubsequentCallingPoints []calling_point_set `json:"subsequentCallingPoints" xml:"subsequentCallingPoints>callingPointList"`
+}
+
+// one service with all of its calling points, from GetServiceDetails
+type service_details struct {
+	GeneratedAt             string              `json:"generatedAt" xml:"generatedAt"`
+	ServiceType             string              `json:"serviceType" xml:"serviceType"`
+	LocationName            string              `json:"locationName" xml:"locationName"`
+	Crs                     string              `json:"crs" xml:"crs"`
+	Operator                string              `json:"operator" xml:"operator"`
+	OperatorCode            string              `json:"operatorCode" xml:"operatorCode"`
+	Rsid                    string              `json:"rsid" xml:"rsid"`
+	IsCancelled             bool                `json:"isCancelled" xml:"isCancelled"`
+	CancelReason            reason              `json:"cancelReason" xml:"cancelReason"`
+	DelayReason             reason              `json:"delayReason" xml:"delayReason"`
+	OverdueMessage          string              `json:"overdueMessage" xml:"overdueMessage"`
+	Length                  int                 `json:"length" xml:"length"`
+	DetachFront             bool                `json:"detachFront" xml:"detachFront"`
+	IsReverseFormation      bool                `json:"isReverseFormation" xml:"isReverseFormation"`
+	Platform                string              `json:"platform" xml:"platform"`
+	Sta                     string              `json:"sta" xml:"sta"`
+	Eta                     string              `json:"eta" xml:"eta"`
+	Ata                     string              `json:"ata" xml:"ata"`
+	Std                     string              `json:"std" xml:"std"`
+	Etd                     string              `json:"etd" xml:"etd"`
+	Atd                     string              `json:"atd" xml:"atd"`
+	AdhocAlerts             []string            `json:"adhocAlerts" xml:"adhocAlerts>adhocAlertText"`
+	Formation               *formation          `json:"formation" xml:"formation"`
+	PreviousCallingPoints   []calling_point_set `json:"previousCallingPoints" xml:"previousCallingPoints>callingPointList"`
+	SubsequentCallingPoints []calling_point_set `json:"subsequentCallingPoints" xml:"subsequentCallingPoints>callingPointList"`
+}
+
+// origin or destination of a service
+type service_location struct {
+	LocationName     string `json:"locationName" xml:"locationName"`
+	Crs              string `json:"crs" xml:"crs"`
+	Via              string `json:"via" xml:"via"`
+	FutureChangeTo   string `json:"futureChangeTo" xml:"futureChangeTo"`
+	AssocIsCancelled bool   `json:"assocIsCancelled" xml:"assocIsCancelled"`
+}
+
+// list of calling points, one list per portion of a dividing train
+type calling_point_set struct {
+	CallingPoint          []calling_point `json:"callingPoint" xml:"callingPoint"`
+	ServiceType           string          `json:"serviceType" xml:"serviceType,attr"`
+	ServiceChangeRequired bool            `json:"serviceChangeRequired" xml:"serviceChangeRequired,attr"`
+	AssocIsCancelled      bool            `json:"assocIsCancelled" xml:"assocIsCancelled,attr"`
+}
+
+type calling_point struct {
+	LocationName        string     `json:"locationName" xml:"locationName"`
+	Crs                 string     `json:"crs" xml:"crs"`
+	St                  string     `json:"st" xml:"st"`
+	Et                  string     `json:"et" xml:"et"`
+	At                  string     `json:"at" xml:"at"`
+	IsCancelled         bool       `json:"isCancelled" xml:"isCancelled"`
+	Length              int        `json:"length" xml:"length"`
+	DetachFront         bool       `json:"detachFront" xml:"detachFront"`
+	Formation           *formation `json:"formation" xml:"formation"`
+	AdhocAlerts         []string   `json:"adhocAlerts" xml:"adhocAlerts>adhocAlertText"`
+	AffectedByDiversion bool       `json:"affectedByDiversion" xml:"affectedByDiversion"`
+	RerouteDelay        int        `json:"rerouteDelay" xml:"rerouteDelay"`
+}
+
+type formation struct {
+	AvgLoading          int     `json:"avgLoading" xml:"avgLoading"`
+	AvgLoadingSpecified bool    `json:"avgLoadingSpecified" xml:"avgLoadingSpecified"`
+	Coaches             []coach `json:"coaches" xml:"coaches>coach"`
+}
+
+type coach struct {
+	CoachClass       string `json:"coachClass" xml:"coachClass"`
+	Loading          int    `json:"loading" xml:"loading"`
+	LoadingSpecified bool   `json:"loadingSpecified" xml:"loadingSpecified"`
+	Number           string `json:"number" xml:"number,attr"`
+}
+
+// average loading in percent, from the coaches if not given, -1 if unknown
+func (f formation) loading() int {
+	if f.AvgLoadingSpecified {
+		return f.AvgLoading
+	}
+	total, count := 0, 0
+	for _, c := range f.Coaches {
+		if c.LoadingSpecified {
+			total += c.Loading
+			count++
+		}
+	}
+	if count == 0 {
+		return -1
+	}
+	return total / count
+}
+
+// how busy a loading percentage feels
+func loading_category(percent int) string {
+	switch {
+	case percent < 0:
+		return ""
+	case percent < 30:
+		return "Quiet"
+	case percent < 70:
+		return "Busy"
+	default:
+		return "Very busy"
+	}
+}
+
+// cancel and delay reasons come as plain strings in most responses,
+// but some versions wrap them in an object with the text in Value
+type reason string
+
+func (r *reason) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*r = reason(text)
+		return nil
+	}
+	var wrapped struct {
+		Value string `json:"Value"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return fmt.Errorf("reason is neither a string nor an object: %w", err)
+	}
+	*r = reason(wrapped.Value)
+	return nil
+}
+
+// same shape as station_board, but services are kept raw
+// so that each one can be decoded on its own
+type raw_board struct {
+	station_board
+	TrainServices []json.RawMessage `json:"trainServices"`
+	BusServices   []json.RawMessage `json:"busServices"`
+	FerryServices []json.RawMessage `json:"ferryServices"`
+}
+
+// decode a board, skipping (and logging) services that cannot be decoded
+// instead of failing the whole board
+func decode_board(body []byte) (station_board, error) {
+	var raw raw_board
+	err := json.Unmarshal(body, &raw)
+	if err != nil {
+		return station_board{}, fmt.Errorf("cannot read board: %w", err)
+	}
+
+	board := raw.station_board
+	board.TrainServices = decode_services(raw.TrainServices, "train", board.Crs)
+	board.BusServices = decode_services(raw.BusServices, "bus", board.Crs)
+	board.FerryServices = decode_services(raw.FerryServices, "ferry", board.Crs)
+	return board, nil
+}
+
+// same shape as departures_board, but services are kept raw
+type raw_departures struct {
+	departures_board
+	Departures []struct {
+		Crs     string          `json:"crs"`
+		Service json.RawMessage `json:"service"`
+	} `json:"departures"`
+}
+
+// decode a departures board, a service that cannot be decoded
+// is logged and its destination left without one
+func decode_departures(body []byte) (departures_board, error) {
+	var raw raw_departures
+	err := json.Unmarshal(body, &raw)
+	if err != nil {
+		return departures_board{}, fmt.Errorf("cannot read departures: %w", err)
+	}
+
+	board := raw.departures_board
+	board.Departures = make([]departure_item, 0, len(raw.Departures))
+	for _, dep := range raw.Departures {
+		item := departure_item{Crs: dep.Crs}
+		if len(dep.Service) > 0 && string(dep.Service) != "null" {
+			if services := decode_services([]json.RawMessage{dep.Service}, "train", board.Crs); len(services) == 1 {
+				item.Service = &services[0]
+			}
+		}
+		board.Departures = append(board.Departures, item)
+	}
+	return board, nil
+}
+
+// drop (and log) a departure service the app cannot use, for sources decoded in one go
+func valid_departures(deps []departure_item, crs string) []departure_item {
+	for i, dep := range deps {
+		if dep.Service == nil {
+			continue
+		}
+		if err := dep.Service.validate(); err != nil {
+			log.Printf("skipping service to %s at %s: %v", dep.Crs, crs, err)
+			deps[i].Service = nil
+		}
+	}
+	return deps
+}
+
+func decode_services(raws []json.RawMessage, kind, crs string) []service_item {
+	services := make([]service_item, 0, len(raws))
+	for i, raw := range raws {
+		var s service_item
+		err := json.Unmarshal(raw, &s)
+		if err == nil {
+			err = s.validate()
+		}
+		if err != nil {
+			log.Printf("skipping %s service %d at %s: %v", kind, i, crs, err)
+			continue
+		}
+		services = append(services, s)
+	}
+	return services
+}
+
+// drop (and log) services the app cannot use, for sources decoded in one go
+func valid_services(services []service_item, kind, crs string) []service_item {
+	res := make([]service_item, 0, len(services))
+	for i, s := range services {
+		if err := s.validate(); err != nil {
+			log.Printf("skipping %s service %d at %s: %v", kind, i, crs, err)
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// check the fields the app cannot do without
+func (s service_item) validate() error {
+	if s.Std == "" && s.Sta == "" {
+		return errors.New("no scheduled time")
+	}
+	return nil
+}
+
+func decode_details(body []byte) (service_details, error) {
+	var details service_details
+	err := json.Unmarshal(body, &details)
+	if err != nil {
+		return service_details{}, fmt.Errorf("cannot read service details: %w", err)
+	}
+	return details, nil
+}
+
+// crs of the first location, ? if there is none
+func first_crs(locs []service_location) string {
+	if len(locs) == 0 || locs[0].Crs == "" {
+		return "?"
+	}
+	return locs[0].Crs
+}
+
+// destinations of a service for the table, e.g. "RAM & DVP" for a train that
+// divides or "BRI via Bath", ? if there are none
+func locations_text(locs []service_location) string {
+	if len(locs) == 0 {
+		return "?"
+	}
+	parts := make([]string, 0, len(locs))
+	for _, loc := range locs {
+		part := loc.Crs
+		if part == "" {
+			part = "?"
+		}
+		if loc.Via != "" {
+			part += " " + loc.Via
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " & ")
+}
+
+// first calling point after this station at the given crs
+func (s service_item) calling_at(crs string) (calling_point, bool) {
+	for _, set := range s.SubsequentCallingPoints {
+		for _, cp := range set.CallingPoint {
+			if cp.Crs == crs {
+				return cp, true
+			}
+		}
+	}
+	return calling_point{}, false
+}
+
+// minutes since midnight of a "15:39" time
+// false for texts like "On time", "Delayed" or "Cancelled"
+func hhmm_minutes(s string) (int, bool) {
+	if len(s) != 5 || s[2] != ':' {
+		return 0, false
+	}
+	hours, err := strconv.Atoi(s[0:2])
+	if err != nil {
+		return 0, false
+	}
+	mins, err := strconv.Atoi(s[3:5])
+	if err != nil {
+		return 0, false
+	}
+	return hours*60 + mins, true
+}
+
+// expected time if given as a time, otherwise the scheduled one
+func best_time(scheduled, estimated string) (int, bool) {
+	if mins, ok := hhmm_minutes(estimated); ok {
+		return mins, true
+	}
+	if estimated == "Cancelled" {
+		return 0, false
+	}
+	return hhmm_minutes(scheduled)
+}
+
+// journey time between leaving and arriving, e.g. 45m or 1h05
+func journey_time(std, etd, sta, eta string) string {
+	dep, ok := best_time(std, etd)
+	if !ok {
+		return ""
+	}
+	arr, ok := best_time(sta, eta)
+	if !ok {
+		return ""
+	}
+	mins := arr - dep
+	if mins < 0 {
+		mins += 24 * 60 // past midnight
+	}
+	if mins < 60 {
+		return fmt.Sprintf("%dm", mins)
+	}
+	return fmt.Sprintf("%dh%02d", mins/60, mins%60)
+}
+
+// convert to the data needed in the table
+func (s service_item) to_train_service() train_service {
+	var ts train_service
+	ts.std = s.Std
+	ts.etd = s.Etd
+	ts.sta = s.Sta
+	ts.eta = s.Eta
+	ts.plat = s.Platform
+	if ts.plat == "" {
+		ts.plat = "?"
+	}
+	ts.dest = locations_text(s.Destination)
+	ts.dests = s.Destination
+	ts.origin = first_crs(s.Origin)
+	ts.operator = s.Operator
+	ts.toc = s.OperatorCode
+	ts.service_id = s.ServiceID
+	ts.mode = s.ServiceType
+	ts.length = s.Length
+	ts.loading = -1
+	if s.Formation != nil {
+		if ts.length == 0 {
+			ts.length = len(s.Formation.Coaches)
+		}
+		ts.loading = s.Formation.loading()
+		ts.coaches = s.Formation.Coaches
+	}
+	ts.cancelled = s.IsCancelled || s.FilterLocationCancelled
+	ts.cancel_reason = string(s.CancelReason)
+	ts.delay_reason = string(s.DelayReason)
+	for _, alert := range s.AdhocAlerts {
+		ts.alerts = append(ts.alerts, html_to_text(alert))
+	}
+	return ts
+}
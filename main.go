@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"os"
 	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -19,6 +25,8 @@ import (
 //go:generate go run fyne.io/tools/cmd/fyne@latest bundle -o bundled.go FyneApp.toml
 //go:generate go run fyne.io/tools/cmd/fyne@latest bundle -o bundled.go -append stations.json
 //go:generate go run fyne.io/tools/cmd/fyne@latest bundle -o bundled.go -append toc.json
+//go:generate curl -sSfo bank_holidays.json https://www.gov.uk/bank-holidays.json
+//go:generate go run fyne.io/tools/cmd/fyne@latest bundle -o bundled.go -append bank_holidays.json
 
 // longest station CRS ['BHA', 'CGT', 'EDA', 'LCB', 'STI', 'SWB', 'SWC', 'WER', 'WHH', 'XWX', 'ZHS']
 // longest station names
@@ -38,29 +46,93 @@ import (
 // all with 30 characters, seems like it cant store more and truncates
 // Edinburgh... and Whitehill... has missing closing brackets
 
-// catch the api
-type return_data struct {
-	TrainServices []any `json:"trainServices"`
-}
-
 // needed data for each train service
 type train_service struct {
-	std      string
-	etd      string
-	plat     string
-	dest     string
-	operator string
-	toc      string
+	std        string
+	etd        string
+	sta        string
+	eta        string
+	plat       string
+	dest       string // every destination with its via text
+	origin     string
+	operator   string
+	toc        string
+	service_id string
+	dest_sta   string // at the filter destination of a departure board
+	dest_eta   string
+	to_crs     string             // destination asked for on next and fastest boards
+	dests      []service_location // trains that divide have more than one
+	mode       string             // train, bus or ferry
+	length     int                // coaches, 0 if unknown
+	loading    int                // average percent full, -1 if unknown
+	coaches    []coach
+
+	cancelled     bool
+	cancel_reason string
+	delay_reason  string
+	alerts        []string
+}
+
+// anything worth tapping the indicator for
+func (ts train_service) has_info() bool {
+	return ts.cancelled || ts.cancel_reason != "" || ts.delay_reason != "" || len(ts.alerts) > 0
 }
 
+// board types of a quick time
+const (
+	board_dep     string = "dep"     // departures from Org, filtered to Dest
+	board_arr     string = "arr"     // arrivals at Dest, filtered from Org
+	board_next    string = "next"    // next train from Org to each of Dests
+	board_fastest string = "fastest" // first train to arrive at each of Dests
+)
+
 // catch quick time json settings
 type quick_time struct {
-	Id    int    `json:"id"`
-	Start string `json:"start"`
-	End   string `json:"end"`
-	Org   string `json:"org"`
-	Dest  string `json:"dest"`
-	Days  []int  `json:"days"`
+	Id    int      `json:"id"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	Org   string   `json:"org"`
+	Dest  string   `json:"dest"`
+	Days  []int    `json:"days"`
+	Board string   `json:"board"`
+	Dests []string `json:"dests"` // destinations of next and fastest boards, instead of Dest
+
+	Hide_replacements bool `json:"hide_replacements"` // only trains, no replacement buses or ferries
+
+	Offset int `json:"offset"` // minutes from now the board starts, e.g. time to walk to the station
+	Window int `json:"window"` // minutes the board covers, 0 for the api default
+
+	Skip_dates  []string `json:"skip_dates"`  // dates or ranges like 2026-12-24..2027-01-02 with no board
+	Extra_dates []string `json:"extra_dates"` // dates with a board even if not one of Days
+	Holidays    string   `json:"holidays"`    // bank holiday region to skip, "" for none
+
+	Priority int `json:"priority"` // lower shows first, ties keep the order of the file
+}
+
+// board type, entries saved before arrivals existed are departures
+func (qt quick_time) board_type() string {
+	switch qt.Board {
+	case board_arr, board_next, board_fastest:
+		return qt.Board
+	}
+	return board_dep
+}
+
+// one row per destination instead of one per train
+func is_departures_board(kind string) bool {
+	return kind == board_next || kind == board_fastest
+}
+
+// one card on the home tab
+type board struct {
+	kind     string // board_dep, board_arr, board_next or board_fastest
+	filtered bool   // has a filter station
+	services []train_service
+	messages []string // station disruption messages
+	title    string   // CRS codes
+	subtitle string   // station names
+	err      error    // why the board could not be fetched
+	fetched  time.Time
 }
 
 type metadata struct {
@@ -78,6 +150,8 @@ type TableConfig struct {
 	CellTemplateText   string     // Placeholder text for data cell templates
 	HeaderTemplateText string     // Placeholder text for header cell templates
 	CornerHeaderText   string     // Text for the top-left corner header cell
+
+	OnSelected func(pos widget.TableCellID) // Called for cells that are not CRS or TOC codes
 }
 
 type tocs struct {
@@ -173,6 +247,7 @@ func (tc *TableConfig) BuildTable(mywin_addr *fyne.Window) *widget.Table {
 		}
 	}
 	table.OnSelected = func(pos widget.TableCellID) {
+		defer table.Unselect(pos) // so the same cell can be tapped again
 		cell_data := tc.Data[pos.Row][pos.Col]
 		err := crs_validator(cell_data)
 		if err == nil {
@@ -223,14 +298,37 @@ func (tc *TableConfig) BuildTable(mywin_addr *fyne.Window) *widget.Table {
 
 			}
 		}
+		if tc.OnSelected != nil {
+			tc.OnSelected(pos)
+		}
 	}
 	return table
 }
 
 type settings struct {
-	Freq        float64 `json:"freq"`
+	Version int `json:"version"` // see migrate.go
+
+	Freq        float64 `json:"freq"`     // seconds between refreshes, see cadence.go
+	Min_freq    float64 `json:"min_freq"` // seconds, when a train is about to go
+	Max_freq    float64 `json:"max_freq"` // seconds, when the window is in the background
+	Timeout     float64 `json:"timeout"`  // seconds per request
 	Key         string  `json:"key"`
+	Arr_key     string  `json:"arr_key"`
+	Svc_key     string  `json:"svc_key"`
 	Desired_len int     `json:"desired_len"`
+	Cache_ttl   float64 `json:"cache_ttl"`  // seconds a board is reused for
+	Max_boards  int     `json:"max_boards"` // boards on the home tab at once
+
+	Columns []string `json:"columns"` // optional columns, from extra_column_names
+
+	Source       string `json:"source"` // source_rdm or source_darwin
+	Darwin_token string `json:"darwin_token"`
+
+	Demo          bool   `json:"demo"` // use the built in mock server
+	Demo_scenario string `json:"demo_scenario"`
+
+	Record bool   `json:"record"` // save raw responses for bug reports
+	Replay string `json:"replay"` // recording to read instead of the network, "" for off
 }
 
 func crs_to_name(crs string) (string, error) {
@@ -265,111 +363,249 @@ func crs_to_name(crs string) (string, error) {
 	}
 }
 
-// use configured data to get data of train services
-func trains(key string, rootURI fyne.URI, numRows int) ([][]train_service, [][2]string, int, error) {
-	const base_url string = "https://api1.raildata.org.uk/1010-live-departure-board-dep1_2/LDBWS/api/20220120/GetDepartureBoard/"
+const (
+	max_parallel_boards int = 4 // boards fetched at once, to be kind to the api
+	default_max_boards  int = 2
+)
 
+// use configured data to get data of train services
+func trains(ctx context.Context, src DepartureSource, s settings, rootURI fyne.URI) ([]board, error) {
 	//crs = strings.ToUpper(strings.TrimSpace(crs))
 
 	// var dep_api_key = os.Getenv("dep_key")
 	_, qts, err := load_json("qtt.json", rootURI)
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, err
 	}
 
-	var now time.Time
-	const current_tz string = " UTC"
-	utcNow := time.Now().UTC()
-
-	if IsUKUsingSummerTime() {
-		now = utcNow.Add(time.Hour)
-	} else {
-		now = utcNow
-	}
-
-	var today int = int(now.Weekday())
+	matches := match_quick_times(qts.Quick_times, uk_now(), s.Max_boards)
 	correct_time := make([]quick_time, 0)
+	for _, m := range matches {
+		if m.shown {
+			correct_time = append(correct_time, m.qt)
+		}
+	}
+	if len(correct_time) == 0 {
+		return nil, nil // not in any time ranges
+	}
+	// boards are fetched at the same time, each one fails on its own
+	res := make([]board, len(correct_time))
+	sem := make(chan struct{}, max_parallel_boards)
+	var wg sync.WaitGroup
+	for i, v := range correct_time {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			res[i] = fetch_board(ctx, src, s, v)
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return res, nil
+}
 
-	date_only := now.Format(time.RFC822)
-	date_only = date_only[0:10]
-	var correct_count int
-
-	for _, qt := range qts.Quick_times {
-		// if today is chosen
-		if slices.Contains(qt.Days, today) {
-			start, err := time.Parse(time.RFC822, date_only+qt.Start+current_tz)
-			if err != nil {
-				return nil, nil, 0, err
-			}
-			end, err := time.Parse(time.RFC822, date_only+qt.End+current_tz)
-			if err != nil {
-				return nil, nil, 0, err
-			}
+// one board of a quick time, a failed request is kept in err
+func fetch_board(ctx context.Context, src DepartureSource, s settings, v quick_time) board {
+	kind := v.board_type()
+	b := board{kind: kind, title: fmt.Sprintf("%s to %s", v.Org, v.Dest)}
+	if is_departures_board(kind) {
+		b.title = fmt.Sprintf("%s to %s", v.Org, strings.Join(v.Dests, ", "))
+	}
 
-			// if within time range
-			if now.After(start) && now.Before(end) {
-				correct_time = append(correct_time, qt)
-				correct_count++
-				if correct_count >= 2 {
-					break // more than two within time
-				}
-			}
+	org_name, err := crs_to_name(v.Org)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	if is_departures_board(kind) {
+		// the names of a list are too long
+		b.subtitle = fmt.Sprintf("%s, %s train to each", org_name, kind)
+	} else {
+		dest_name, err := crs_to_name(v.Dest)
+		if err != nil {
+			b.err = err
+			return b
 		}
+		b.subtitle = fmt.Sprintf("%s to %s", org_name, dest_name)
 	}
-	if len(correct_time) == 0 {
-		return nil, nil, 0, nil // not in any time ranges
-	}
-	res := make([][]train_service, 0, len(correct_time))
-	f_t_list := make([][2]string, 0, len(correct_time))
-	for _, v := range correct_time {
-		var url string = base_url + v.Org
-		var params string
-		var err error
-		if v.Dest != "*" {
-			params, err = format_params([]string{"filterCrs", "filterType", "numRows"},
-				[]string{v.Dest, "to", fmt.Sprint(numRows)}) // has filter dest
-		} else {
-			params, err = format_params([]string{"numRows"},
-				[]string{fmt.Sprint(numRows)}) // no filter dest
+	if v.Offset != 0 || v.Window > 0 {
+		window := v.Window
+		if window <= 0 {
+			window = max_time_window
 		}
+		b.subtitle += fmt.Sprintf(", in %d to %d mins", v.Offset, v.Offset+window)
+	}
 
-		if err != nil {
-			return nil, nil, 0, err
+	// departures are listed at the origin, arrivals at the destination
+	q := board_query{crs: v.Org, filter_crs: v.Dest, filter_type: "to", num_rows: s.Desired_len}
+	if kind == board_arr {
+		q = board_query{crs: v.Dest, filter_crs: v.Org, filter_type: "from", num_rows: s.Desired_len}
+	} else if is_departures_board(kind) {
+		q = board_query{crs: v.Org, filter_crs: "*", filter_list: v.Dests}
+	}
+	q.time_offset, q.time_window = v.Offset, v.Window
+	b.filtered = q.filter_crs != "*"
+
+	switch kind {
+	case board_next, board_fastest:
+		var db departures_board
+		if kind == board_next {
+			db, err = src.NextDepartures(ctx, q)
 		} else {
-			url = url + params // concat paremeters to url
+			db, err = src.FastestDepartures(ctx, q)
 		}
-		this_res, err := request(url, key)
-		if err != nil {
-			return nil, nil, 0, err
+		if err == nil {
+			b.services, b.messages = departures_services(db, v.Dests)
+			b.fetched = db.fetched
+		}
+	case board_arr:
+		var sb station_board
+		sb, err = src.Arrivals(ctx, q)
+		if err == nil {
+			b.services, b.messages = board_services(sb, "*", v.Hide_replacements)
+			b.fetched = sb.fetched
 		}
-		res = append(res, this_res) // append this request to list of requests
+	default:
+		var sb station_board
+		sb, err = src.Departures(ctx, q)
+		if err == nil {
+			// look for arrival time at destination
+			b.services, b.messages = board_services(sb, q.filter_crs, v.Hide_replacements)
+			b.fetched = sb.fetched
+		}
+	}
+	b.err = err
+	return b
+}
 
-		org_name, err := crs_to_name(v.Org)
-		if err != nil {
-			return nil, nil, 0, err
+// one column of a train times table
+type tt_column struct {
+	header string
+	width  float32
+	value  func(ts train_service) string
+	tap    func(ts train_service, mywin_addr *fyne.Window) // optional, instead of service details
+}
+
+// indicator for services with a reason or an alert, tap to read it
+var info_column = tt_column{header: "!", width: 30,
+	value: func(ts train_service) string {
+		if ts.has_info() {
+			return "!"
 		}
-		dest_name, err := crs_to_name(v.Dest)
-		if err != nil {
-			return nil, nil, 0, err
+		return ""
+	},
+	tap: show_reasons}
+
+// marks replacement buses and ferries, empty for trains
+var mode_column = tt_column{header: "Mode", width: 50,
+	value: func(ts train_service) string {
+		switch ts.mode {
+		case "bus":
+			return "Bus"
+		case "ferry":
+			return "Ferry"
 		}
+		return ""
+	}}
+
+var dep_columns = []tt_column{
+	mode_column,
+	{header: "Plat", width: 40, value: func(ts train_service) string { return ts.plat }},
+	{header: "TOC", width: 40, value: func(ts train_service) string { return ts.toc }},
+	{header: "STD", width: 60, value: func(ts train_service) string { return ts.std }},
+	{header: "Dest", width: 110, value: func(ts train_service) string { return ts.dest }, tap: show_destinations},
+	{header: "ETD", width: 80, value: func(ts train_service) string { return ts.etd }},
+	info_column,
+}
 
-		f_t_list = append(f_t_list, [2]string{fmt.Sprintf("%s to %s", v.Org, v.Dest),
-			fmt.Sprintf("%s to %s", org_name, dest_name)})
-		// title string list
+var arr_columns = []tt_column{
+	mode_column,
+	{header: "Plat", width: 40, value: func(ts train_service) string { return ts.plat }},
+	{header: "TOC", width: 40, value: func(ts train_service) string { return ts.toc }},
+	{header: "STA", width: 60, value: func(ts train_service) string { return ts.sta }},
+	{header: "Origin", width: 50, value: func(ts train_service) string { return ts.origin }},
+	{header: "ETA", width: 80, value: func(ts train_service) string { return ts.eta }},
+	info_column,
+}
+
+// extra columns for departures to one destination
+var dest_columns = []tt_column{
+	{header: "STA", width: 60, value: func(ts train_service) string { return ts.dest_sta }},
+	{header: "ETA", width: 80, value: func(ts train_service) string { return ts.dest_eta }},
+	{header: "Dur", width: 50, value: func(ts train_service) string { return journey_time(ts.std, ts.etd, ts.dest_sta, ts.dest_eta) }},
+}
+
+// one row per destination asked for, with the train going there
+var next_columns = slices.Concat([]tt_column{
+	{header: "To", width: 50, value: func(ts train_service) string { return ts.to_crs }},
+	mode_column,
+	{header: "Plat", width: 40, value: func(ts train_service) string { return ts.plat }},
+	{header: "TOC", width: 40, value: func(ts train_service) string { return ts.toc }},
+	{header: "STD", width: 60, value: func(ts train_service) string { return ts.std }},
+	{header: "ETD", width: 80, value: func(ts train_service) string { return ts.etd }},
+}, dest_columns, []tt_column{info_column})
+
+// optional columns, chosen in settings
+var extra_columns = map[string]tt_column{
+	"Len": {header: "Len", width: 40, value: func(ts train_service) string {
+		if ts.length == 0 {
+			return ""
+		}
+		return strconv.Itoa(ts.length)
+	}},
+	"Load": {header: "Load", width: 60, value: func(ts train_service) string {
+		if ts.loading < 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d%%", ts.loading)
+	}, tap: show_loading},
+}
+var extra_column_names = []string{"Len", "Load"}
+
+// columns of a board, with the chosen extra columns before the indicator
+func board_columns(b board, extras []string) []tt_column {
+	cols := slices.Clone(base_columns(b))
+	at := slices.IndexFunc(cols, func(col tt_column) bool { return col.header == info_column.header })
+	for _, name := range extra_column_names {
+		if slices.Contains(extras, name) {
+			cols = slices.Insert(cols, at, extra_columns[name])
+			at++
+		}
+	}
+	return cols
+}
+
+func base_columns(b board) []tt_column {
+	if b.kind == board_arr {
+		return arr_columns
+	} else if is_departures_board(b.kind) {
+		return next_columns
+	} else if b.filtered {
+		return slices.Concat(dep_columns, dest_columns)
 	}
-	return res, f_t_list, correct_count, nil
+	return dep_columns
 }
 
-func apply_col_widths(table *widget.Table) {
+// next and fastest boards have a row per destination whatever the length setting
+func board_rows(b board, desired_len int) int {
+	if is_departures_board(b.kind) {
+		return len(b.services)
+	}
+	return desired_len
+}
+
+func apply_col_widths(table *widget.Table, cols []tt_column) {
 	table.SetColumnWidth(-1, 30) // number header
-	table.SetColumnWidth(0, 40)  // plat
-	table.SetColumnWidth(1, 40)  // toc
-	table.SetColumnWidth(2, 60)  // std
-	table.SetColumnWidth(3, 50)  // dest
-	table.SetColumnWidth(4, 80)  // etd
+	for i, col := range cols {
+		table.SetColumnWidth(i, col.width)
+	}
 }
 
-func tt_table(ut []train_service, dl int, ch, rh []string, mywin_addr *fyne.Window) *widget.Table {
+func tt_table(ut []train_service, dl int, cols []tt_column, rh []string, mywin_addr *fyne.Window, src DepartureSource) *widget.Table {
 	var data [][]string
 	var datarow []string
 	for i, val := range ut {
@@ -377,31 +613,83 @@ func tt_table(ut []train_service, dl int, ch, rh []string, mywin_addr *fyne.Wind
 			break // if too many services
 		}
 		datarow = nil
-		datarow = append(datarow, val.plat, val.toc, val.std, val.dest, val.etd)
+		for _, col := range cols {
+			datarow = append(datarow, col.value(val))
+		}
 		data = append(data, datarow)
 	}
 
+	ch := make([]string, 0, len(cols))
+	for _, col := range cols {
+		ch = append(ch, col.header)
+	}
+
 	config := NewTableConfig(data, ch, rh)
 	config.CellTemplateText = "?" // put ? for unknown data
+	config.OnSelected = func(pos widget.TableCellID) {
+		if tap := cols[pos.Col].tap; tap != nil && data[pos.Row][pos.Col] != "" {
+			tap(ut[pos.Row], mywin_addr)
+			return
+		} else if ut[pos.Row].service_id == "" {
+			return // e.g. "No train" rows, nothing to drill down into
+		}
+		go show_service_details(ut[pos.Row], src, mywin_addr) // drill down into the row
+	}
 	table := config.BuildTable(mywin_addr)
-	apply_col_widths(table)
+	apply_col_widths(table, cols)
 	return table
 }
 
+// stations shared by boards have the same messages, only show each one once
+func dedupe_messages(boards []board) [][]string {
+	seen := map[string]bool{}
+	res := make([][]string, len(boards))
+	for i, b := range boards {
+		for _, msg := range b.messages {
+			if !seen[msg] {
+				seen[msg] = true
+				res[i] = append(res[i], msg)
+			}
+		}
+	}
+	return res
+}
+
+// card for one board, with a collapsible banner for station messages
+// a board that failed shows why instead, so the other boards still show
+func board_card(b board, table *widget.Table, messages []string) *widget.Card {
+	if b.err != nil {
+		err_label := widget.NewLabel(error_text(b.err))
+		err_label.Wrapping = fyne.TextWrapWord
+		return widget.NewCard(b.title, b.subtitle, err_label)
+	}
+	subtitle := b.subtitle + saved_text(b.fetched, time.Now())
+	if len(messages) == 0 {
+		return widget.NewCard(b.title, subtitle, table)
+	}
+	msg_label := widget.NewLabel(strings.Join(messages, "\n\n"))
+	msg_label.Wrapping = fyne.TextWrapWord
+	banner := widget.NewAccordion(widget.NewAccordionItem(
+		fmt.Sprintf("Station messages (%d)", len(messages)), msg_label))
+	return widget.NewCard(b.title, subtitle, container.NewBorder(banner, nil, nil, nil, table))
+}
+
 // update main label (get data + gui)
 func refershTimes(mylabel_addr **widget.Label,
 	mywin_addr *fyne.Window,
 	hometab_addr **container.TabItem,
 	apptabs_addr **container.AppTabs,
-	key string,
-	desired_len int,
+	s settings,
 	rootURI fyne.URI,
-	ref_button **widget.Button) {
+	ref_button **widget.Button,
+	mode cache_mode) {
 	apptabs_obj := *apptabs_addr
 	if apptabs_obj.SelectedIndex() != 0 {
 		return // not on this page
 	}
 
+	note_attempt() // the next refresh is timed from this one
+
 	mylabel_obj := *mylabel_addr
 	ref_button_obj := *ref_button
 	fyne.Do(func() {
@@ -410,52 +698,55 @@ func refershTimes(mylabel_addr **widget.Label,
 
 	mywin_obj := *mywin_addr
 
-	updated_times_s, f_t_list, correct_count, err := trains(key, rootURI, desired_len)
+	// a newer refresh or leaving the tab cancels this one
+	ctx := start_refresh()
+	src, err := new_source(s, rootURI)
 	if err != nil {
-		dialog.ShowError(err, mywin_obj)
+		show_api_error(err, mywin_obj, apptabs_obj, mylabel_obj)
+		return
+	}
+	boards, err := trains(with_cache_mode(ctx, mode), src, s, rootURI)
+	if ctx.Err() != nil {
+		return // someone else is refreshing now
+	}
+	if mode == cache_only && slices.ContainsFunc(boards, func(b board) bool { return errors.Is(b.err, not_cached) }) {
+		return // not all saved, wait for the live boards
+	}
+	if err == nil && len(boards) > 0 && !slices.ContainsFunc(boards, func(b board) bool { return b.err == nil }) {
+		err = boards[0].err // nothing to show at all
+	}
+	if err != nil {
+		show_api_error(err, mywin_obj, apptabs_obj, mylabel_obj)
+		return // keep showing the last boards
 	}
 
+	note_boards(boards)
 	hometab_obj := *hometab_addr
 
-	colHeaders := []string{"Plat", "TOC", "STD", "Dest", "ETD"}
 	var rowHeaders []string
-	for i := range desired_len {
+	for i := range max(s.Desired_len, max_filter_list) {
 		rowHeaders = append(rowHeaders, fmt.Sprintf("%v", i+1))
 	}
-	switch correct_count {
-	case 0: // no correct
-		mylabel_obj := *mylabel_addr
+	messages := dedupe_messages(boards)
+	if len(boards) == 0 { // no correct
 		fyne.Do(func() {
 			mylabel_obj.SetText("not in specified time frames")
 			hometab_obj.Content = container.NewBorder(container.NewHBox(ref_button_obj, mylabel_obj), nil, nil, nil, nil)
 		})
-	case 1: // one correct, whole page
-		table := tt_table(updated_times_s[0], desired_len, colHeaders, rowHeaders, mywin_addr)
-		fyne.Do(func() {
-			mylabel_obj.SetText("")
-			hometab_obj.Content = container.NewBorder(container.NewHBox(ref_button_obj, mylabel_obj), nil, nil, nil, container.NewScroll(widget.NewCard(f_t_list[0][0], f_t_list[0][1], table)))
-		})
-
-	case 2: // two correct, split page
-		table := tt_table(updated_times_s[0], desired_len, colHeaders, rowHeaders, mywin_addr)
-		table2 := tt_table(updated_times_s[1], desired_len, colHeaders, rowHeaders, mywin_addr)
-
-		fyne.Do(func() {
-			mylabel_obj.SetText("")
-
-			hometab_obj.Content = container.NewBorder(container.NewHBox(ref_button_obj, mylabel_obj), nil, nil, nil,
-				container.New(NewHalfHeightLayout(),
-					container.NewScroll(
-						widget.NewCard(f_t_list[0][0], f_t_list[0][1], table)),
-					container.NewScroll(
-						widget.NewCard(f_t_list[1][0], f_t_list[1][1], table2)),
-				))
-
-		})
-	default:
-		dialog.ShowConfirm("something went wrong", fmt.Sprintf("incorrect number of correct times (%v)", correct_count), nil, mywin_obj)
-	} // function shouldn't return more than two but just in case
+		return
+	}
 
+	// the page is split evenly between the boards
+	panes := make([]fyne.CanvasObject, 0, len(boards))
+	for i, b := range boards {
+		table := tt_table(b.services, board_rows(b, s.Desired_len), board_columns(b, s.Columns), rowHeaders, mywin_addr, src)
+		panes = append(panes, container.NewScroll(board_card(b, table, messages[i])))
+	}
+	fyne.Do(func() {
+		mylabel_obj.SetText("")
+		hometab_obj.Content = container.NewBorder(container.NewHBox(ref_button_obj, mylabel_obj), nil, nil, nil,
+			container.New(NewSplitHeightLayout(), panes...))
+	})
 }
 
 func tidyUp() {
@@ -470,6 +761,13 @@ func main() {
 	// run when exiting
 	defer tidyUp()
 
+	// serve the mock LDBWS api for development, e.g. QTT_MOCK_ADDR=localhost:8080
+	if addr := os.Getenv("QTT_MOCK_ADDR"); addr != "" {
+		go func() {
+			log.Println(http.ListenAndServe(addr, mock_handler{scenario: os.Getenv("QTT_MOCK_SCENARIO")}))
+		}()
+	}
+
 	myapp := app.NewWithID("qtt")
 	mywin := myapp.NewWindow("Quick Train Times")
 	mywin.Resize(fyne.NewSize(640, 640))
@@ -505,6 +803,31 @@ func main() {
 		}
 	}
 
+	entry_timeout := widget.NewEntry()
+	entry_timeout.SetPlaceHolder("in seconds, per request")
+	entry_timeout.Validator = entry_freq.Validator
+
+	entry_min_freq := widget.NewEntry()
+	entry_min_freq.SetPlaceHolder("in seconds, when a train leaves within 5 minutes")
+	entry_min_freq.Validator = entry_freq.Validator
+
+	entry_max_freq := widget.NewEntry()
+	entry_max_freq.SetPlaceHolder("in seconds, when the window is in the background")
+	entry_max_freq.Validator = entry_freq.Validator
+
+	entry_cache := widget.NewEntry()
+	entry_cache.SetPlaceHolder("in seconds, 0 to always fetch")
+	entry_cache.Validator = func(s string) error {
+		num, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return errors.New("not a number")
+		} else if num < 0 {
+			return errors.New("number must not be negative")
+		} else {
+			return nil
+		}
+	}
+
 	entry_key := widget.NewEntry()
 	entry_key.SetPlaceHolder("48 character long key")
 
@@ -516,6 +839,61 @@ func main() {
 		}
 	}
 
+	entry_arr_key := widget.NewEntry()
+	entry_arr_key.SetPlaceHolder("48 character long key, only for arrival boards")
+
+	entry_arr_key.Validator = func(s string) error {
+		if s != "" && len(s) != 48 {
+			return errors.New("invalid key")
+		} else {
+			return nil
+		}
+	}
+
+	entry_svc_key := widget.NewEntry()
+	entry_svc_key.SetPlaceHolder("48 character long key, only for service details")
+
+	entry_svc_key.Validator = entry_arr_key.Validator
+
+	sourceMapping := map[string]string{
+		"Rail Data Marketplace":     source_rdm,
+		"Darwin OpenLDBWS (legacy)": source_darwin,
+	}
+	source_names := []string{"Rail Data Marketplace", "Darwin OpenLDBWS (legacy)"}
+	source_name := func(src string) string {
+		if src == source_darwin {
+			return source_names[1]
+		}
+		return source_names[0]
+	}
+	select_source := widget.NewSelect(source_names, nil)
+
+	entry_token := widget.NewEntry()
+	entry_token.SetPlaceHolder("36 character long token, only for Darwin")
+	entry_token.Validator = func(s string) error {
+		if s != "" && len(s) != 36 {
+			return errors.New("invalid token")
+		} else {
+			return nil
+		}
+	}
+
+	check_demo := widget.NewCheck("made up trains, no key or network needed", nil)
+	select_scenario := widget.NewSelect(mock_scenarios, nil)
+
+	check_columns := widget.NewCheckGroup(extra_column_names, nil)
+	check_columns.Horizontal = true
+
+	check_record := widget.NewCheck("save raw responses for bug reports", nil)
+	const replay_off string = "Off"
+	select_replay := widget.NewSelect(append([]string{replay_off}, list_recordings(rootURI)...), nil)
+	replay_name := func(fname string) string {
+		if fname == "" {
+			return replay_off
+		}
+		return fname
+	}
+
 	entry_len := widget.NewEntry()
 	entry_len.SetPlaceHolder("positive integer [1,150]")
 	entry_len.Validator = func(s string) error {
@@ -530,21 +908,64 @@ func main() {
 
 	}
 
+	entry_boards := widget.NewEntry()
+	entry_boards.SetPlaceHolder("positive integer [1,10], boards share the page")
+	entry_boards.Validator = func(s string) error {
+		myint, err := strconv.Atoi(s)
+		if err != nil {
+			return errors.New("not an integer")
+		} else if myint <= 0 || myint > 10 {
+			return errors.New("not within [1,10]")
+		} else {
+			return nil
+		}
+	}
+
 	existing_settings, _, err := load_json("settings.json", rootURI)
 	if err != nil {
-		dialog.ShowError(err, mywin)
+		show_load_error(err, mywin, rootURI)
 	}
 
 	entry_freq.SetText(fmt.Sprint(existing_settings.Freq))
+	entry_min_freq.SetText(fmt.Sprint(existing_settings.Min_freq))
+	entry_max_freq.SetText(fmt.Sprint(existing_settings.Max_freq))
+	entry_timeout.SetText(fmt.Sprint(existing_settings.Timeout))
+	entry_cache.SetText(fmt.Sprint(existing_settings.Cache_ttl))
 	entry_key.SetText(existing_settings.Key)
+	entry_arr_key.SetText(existing_settings.Arr_key)
+	entry_svc_key.SetText(existing_settings.Svc_key)
+	select_source.SetSelected(source_name(existing_settings.Source))
+	entry_token.SetText(existing_settings.Darwin_token)
+	check_demo.SetChecked(existing_settings.Demo)
+	select_scenario.SetSelected(existing_settings.Demo_scenario)
+	check_record.SetChecked(existing_settings.Record)
+	select_replay.SetSelected(replay_name(existing_settings.Replay))
 	entry_len.SetText(fmt.Sprint(existing_settings.Desired_len))
+	entry_boards.SetText(fmt.Sprint(existing_settings.Max_boards))
+	check_columns.SetSelected(existing_settings.Columns)
 
 	form := &widget.Form{
 		OnSubmit: func() { // optional, handle form submission
 			var s settings
 			s.Freq, err = strconv.ParseFloat(entry_freq.Text, 64)
+			s.Min_freq, _ = strconv.ParseFloat(entry_min_freq.Text, 64)
+			s.Max_freq, _ = strconv.ParseFloat(entry_max_freq.Text, 64)
+			s.Timeout, _ = strconv.ParseFloat(entry_timeout.Text, 64)
+			s.Cache_ttl, _ = strconv.ParseFloat(entry_cache.Text, 64)
 			s.Key = entry_key.Text
+			s.Arr_key = entry_arr_key.Text
+			s.Svc_key = entry_svc_key.Text
+			s.Source = sourceMapping[select_source.Selected]
+			s.Darwin_token = entry_token.Text
+			s.Demo = check_demo.Checked
+			s.Demo_scenario = select_scenario.Selected
+			s.Record = check_record.Checked
+			if select_replay.Selected != replay_off {
+				s.Replay = select_replay.Selected
+			}
 			s.Desired_len, _ = strconv.Atoi(entry_len.Text)
+			s.Max_boards, _ = strconv.Atoi(entry_boards.Text)
+			s.Columns = check_columns.Selected
 			err := save_json(s, "settings.json", rootURI)
 			if err != nil {
 				dialog.ShowError(err, mywin)
@@ -554,15 +975,43 @@ func main() {
 		},
 		OnCancel: func() {
 			entry_freq.SetText(fmt.Sprint(existing_settings.Freq))
+			entry_min_freq.SetText(fmt.Sprint(existing_settings.Min_freq))
+			entry_max_freq.SetText(fmt.Sprint(existing_settings.Max_freq))
+			entry_timeout.SetText(fmt.Sprint(existing_settings.Timeout))
+			entry_cache.SetText(fmt.Sprint(existing_settings.Cache_ttl))
 			entry_key.SetText(existing_settings.Key)
+			entry_arr_key.SetText(existing_settings.Arr_key)
+			entry_svc_key.SetText(existing_settings.Svc_key)
+			select_source.SetSelected(source_name(existing_settings.Source))
+			entry_token.SetText(existing_settings.Darwin_token)
+			check_demo.SetChecked(existing_settings.Demo)
+			select_scenario.SetSelected(existing_settings.Demo_scenario)
+			check_record.SetChecked(existing_settings.Record)
+			select_replay.SetSelected(replay_name(existing_settings.Replay))
 			entry_len.SetText(fmt.Sprint(existing_settings.Desired_len))
+			entry_boards.SetText(fmt.Sprint(existing_settings.Max_boards))
+			check_columns.SetSelected(existing_settings.Columns)
 		},
 	}
 
 	// append items to form
 	form.Append("Refresh Frequency (secs)", entry_freq)
+	form.Append("Fastest Refresh (secs)", entry_min_freq)
+	form.Append("Slowest Refresh (secs)", entry_max_freq)
+	form.Append("Request Timeout (secs)", entry_timeout)
+	form.Append("Reuse Boards For (secs)", entry_cache)
 	form.Append("Departure API Key", entry_key)
+	form.Append("Arrival API Key", entry_arr_key)
+	form.Append("Service Details API Key", entry_svc_key)
+	form.Append("Data Source", select_source)
+	form.Append("Darwin Token", entry_token)
+	form.Append("Demo Mode", check_demo)
+	form.Append("Demo Scenario", select_scenario)
+	form.Append("Record Responses", check_record)
+	form.Append("Replay Recording", select_replay)
 	form.Append("Max num of train times", entry_len)
+	form.Append("Max num of boards", entry_boards)
+	form.Append("Extra Columns", check_columns)
 	form.SubmitText = "Save"
 
 	con := container.NewBorder(nil, widget.NewLabel("Changes will be applied next time starting the program."), nil, nil, form)
@@ -572,10 +1021,13 @@ func main() {
 
 	mytabs := container.NewAppTabs(home_tab, settings_tab, config_tab)
 	mywin.SetContent(mytabs)
-	refershTimes(&placeholder, &mywin, &home_tab, &mytabs, existing_settings.Key, existing_settings.Desired_len, rootURI, &refresh_button)
+	// show the boards saved last time straight away, then get fresh ones
+	load_cache(rootURI)
+	refershTimes(&placeholder, &mywin, &home_tab, &mytabs, existing_settings, rootURI, &refresh_button, cache_only)
+	go refershTimes(&placeholder, &mywin, &home_tab, &mytabs, existing_settings, rootURI, &refresh_button, cache_normal)
 
 	refresh_button.OnTapped = func() {
-		go refershTimes(&placeholder, &mywin, &home_tab, &mytabs, existing_settings.Key, existing_settings.Desired_len, rootURI, &refresh_button)
+		go refershTimes(&placeholder, &mywin, &home_tab, &mytabs, existing_settings, rootURI, &refresh_button, cache_force)
 		fyne.Do(func() { mywin.SetContent(mytabs) })
 	}
 
@@ -583,9 +1035,10 @@ func main() {
 	mytabs.OnSelected = func(selectedTab *container.TabItem) {
 		if mytabs.SelectedIndex() == 0 {
 			fyne.Do(func() { placeholder.SetText("refreshing train times") })
-			go refershTimes(&placeholder, &mywin, &home_tab, &mytabs, existing_settings.Key, existing_settings.Desired_len, rootURI, &refresh_button)
+			go refershTimes(&placeholder, &mywin, &home_tab, &mytabs, existing_settings, rootURI, &refresh_button, cache_normal)
 			fyne.Do(func() { mywin.SetContent(mytabs) })
 		} else {
+			cancel_refresh() // nobody is looking at the result
 			placeholder.SetText("refreshing train times")
 			// home_tab.Content = placeholder
 		}
@@ -597,13 +1050,15 @@ func main() {
 		dialog.ShowError(err, mywin)
 	}
 
-	go func() {
-		// main loop
-		for range time.Tick(time.Second * time.Duration(existing_settings.Freq)) {
-			refershTimes(&placeholder, &mywin, &home_tab, &mytabs, existing_settings.Key, existing_settings.Desired_len, rootURI, &refresh_button)
-			fyne.Do(func() { mywin.SetContent(mytabs) })
-		}
-	}()
+	// refresh less often in the background
+	myapp.Lifecycle().SetOnEnteredForeground(func() { set_focused(true) })
+	myapp.Lifecycle().SetOnExitedForeground(func() { set_focused(false) })
+
+	// main loop
+	go run_scheduler(existing_settings, rootURI, func() {
+		refershTimes(&placeholder, &mywin, &home_tab, &mytabs, existing_settings, rootURI, &refresh_button, cache_normal)
+		fyne.Do(func() { mywin.SetContent(mytabs) })
+	})
 
 	mywin.Show()
 	myapp.Run()
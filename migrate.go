@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// this code file keeps settings.json and qtt.json readable as they change
+// each file has a version, older files are upgraded one version at a time after a backup
+
+const (
+	settings_version int = 1
+	qtt_version      int = 1
+)
+
+// current version of each file with a schema
+var schema_versions = map[string]int{
+	"settings.json": settings_version,
+	"qtt.json":      qtt_version,
+}
+
+// migrations[fname][v] upgrades a file from version v to v+1
+var migrations = map[string][]func(doc map[string]any) error{
+	"settings.json": {settings_v0_to_v1},
+	"qtt.json":      {qtt_v0_to_v1},
+}
+
+// files from before versions, fields added since get their defaults written out
+func settings_v0_to_v1(doc map[string]any) error {
+	defaults := map[string]any{
+		"timeout":    default_timeout,
+		"source":     source_rdm,
+		"cache_ttl":  default_cache_ttl,
+		"max_boards": default_max_boards,
+		"min_freq":   default_min_freq,
+		"max_freq":   default_max_freq,
+	}
+	for key, val := range defaults {
+		if _, ok := doc[key]; !ok {
+			doc[key] = val
+		}
+	}
+	return nil
+}
+
+// entries from before arrival boards have no board type
+func qtt_v0_to_v1(doc map[string]any) error {
+	list, ok := doc["quick_times"].([]any)
+	if !ok && doc["quick_times"] != nil {
+		return errors.New("quick_times is not a list")
+	}
+	for _, item := range list {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			return errors.New("a quick time is not an object")
+		}
+		if board, _ := entry["board"].(string); board == "" {
+			entry["board"] = board_dep
+		}
+	}
+	return nil
+}
+
+// a file that cannot be read or upgraded, offered to be reset or restored
+type broken_file_error struct {
+	fname string
+	err   error
+}
+
+func (e *broken_file_error) Error() string {
+	return fmt.Sprintf("%s cannot be read: %v", e.fname, e.err)
+}
+
+func (e *broken_file_error) Unwrap() error { return e.err }
+
+// content of fname at the current version, the file is upgraded in place if older
+func migrate(fname string, content []byte, rootURI fyne.URI) ([]byte, error) {
+	current, ok := schema_versions[fname]
+	if !ok {
+		return content, nil // no schema
+	}
+	doc := map[string]any{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, &broken_file_error{fname: fname, err: err}
+	}
+
+	version := 0 // files from before versions
+	if val, ok := doc["version"]; ok {
+		num, ok := val.(float64)
+		if !ok || num != float64(int(num)) || num < 0 {
+			return nil, &broken_file_error{fname: fname, err: fmt.Errorf("version %v is not a version", val)}
+		}
+		version = int(num)
+	}
+	if version > current {
+		return nil, &broken_file_error{fname: fname, err: fmt.Errorf("version %d is from a newer version of the app", version)}
+	} else if version == current {
+		return content, nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", fname, version)
+	if err := write_file(content, backup, rootURI); err != nil {
+		return nil, fmt.Errorf("cannot back up %s before upgrading it: %w", fname, err)
+	}
+	for v := version; v < current; v++ {
+		if err := migrations[fname][v](doc); err != nil {
+			return nil, &broken_file_error{fname: fname, err: fmt.Errorf("cannot upgrade from version %d: %w", v, err)}
+		}
+	}
+	doc["version"] = current
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := write_file(upgraded, fname, rootURI); err != nil {
+		return nil, err
+	}
+	return upgraded, nil
+}
+
+// backups of fname, the last good one first, then the ones from before upgrades, newest first
+func backup_names(fname string, rootURI fyne.URI) []string {
+	uris, err := storage.List(rootURI)
+	if err != nil {
+		return nil
+	}
+	var last_good, upgrades []string
+	for _, u := range uris {
+		switch name := u.Name(); {
+		case name == fname+".bak":
+			last_good = append(last_good, name)
+		case strings.HasPrefix(name, fname+".v") && strings.HasSuffix(name, ".bak"):
+			upgrades = append(upgrades, name)
+		}
+	}
+	slices.Sort(upgrades)
+	slices.Reverse(upgrades)
+	return append(last_good, upgrades...)
+}
+
+// put backup in place of fname, or the defaults if backup is ""
+// the broken file is kept as fname.broken
+func recover_file(fname, backup string, rootURI fyne.URI) error {
+	if content, err := read_file(fname, rootURI); err == nil {
+		if err := write_file(content, fname+".broken", rootURI); err != nil {
+			return err
+		}
+	}
+	if backup != "" {
+		content, err := read_file(backup, rootURI)
+		if err != nil {
+			return err
+		}
+		return write_file(content, fname, rootURI) // upgraded again next time it loads
+	}
+	myURI, err := storage.Child(rootURI, fname)
+	if err != nil {
+		return err
+	}
+	return storage.Delete(myURI) // load_json writes the defaults
+}
+
+// error dialog for load_json, broken files can be reset or restored
+func show_load_error(err error, mywin fyne.Window, rootURI fyne.URI) {
+	var broken *broken_file_error
+	if !errors.As(err, &broken) {
+		dialog.ShowError(err, mywin)
+		return
+	}
+	fname := broken.fname
+	backups := backup_names(fname, rootURI)
+
+	text := err.Error() + "\n\nReset it to the defaults"
+	if len(backups) > 0 {
+		text += ", or restore the backup " + backups[0]
+	}
+	text += ". The broken file is kept as " + fname + ".broken."
+	label := widget.NewLabel(text)
+	label.Wrapping = fyne.TextWrapWord
+
+	var recovery *dialog.CustomDialog
+	use_backup := func(backup string) {
+		recovery.Hide()
+		if err := recover_file(fname, backup, rootURI); err != nil {
+			dialog.ShowError(err, mywin)
+			return
+		}
+		dialog.ShowInformation("Info", fname+" recovered. Restart the app to use it.", mywin)
+	}
+	reset_button := widget.NewButton("Reset", func() { use_backup("") })
+	restore_button := widget.NewButton("Restore backup", func() { use_backup(backups[0]) })
+	if len(backups) == 0 {
+		restore_button.Disable()
+	}
+	close_button := widget.NewButton("Close", func() { recovery.Hide() })
+
+	recovery = dialog.NewCustomWithoutButtons("Cannot read "+fname, label, mywin)
+	recovery.SetButtons([]fyne.CanvasObject{close_button, restore_button, reset_button})
+	recovery.Resize(fyne.NewSize(480, 240))
+	recovery.Show()
+}
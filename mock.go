@@ -0,0 +1,494 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// this code file is a fake LDBWS server for demo mode, development and screenshots
+// boards are made up from stations.json and toc.json, the same request always
+// gives the same trains so that service details match the boards
+
+// scripted scenarios, chosen in settings
+var mock_scenarios = []string{"mixed", "on time", "delays", "cancellations", "platform changes", "replacement buses"}
+
+var mock_delay_reasons = []string{
+	"This train has been delayed by a signalling problem",
+	"This train has been delayed by a late running train being in front of this one",
+	"This train has been delayed by a shortage of train crew",
+	"This train has been delayed by a fault with the on-train equipment",
+}
+
+var mock_cancel_reasons = []string{
+	"This train has been cancelled because of a fault on this train",
+	"This train has been cancelled because of a shortage of train crew",
+	"This train has been cancelled because of a points failure",
+}
+
+var mock_data struct {
+	once     sync.Once
+	stations []station
+	tocs     []toc
+}
+
+func load_mock_data() {
+	mock_data.once.Do(func() {
+		var sts stations
+		var ts tocs
+		json.Unmarshal(resourceStationsJson.StaticContent, &sts)
+		json.Unmarshal(resourceTocJson.StaticContent, &ts)
+		mock_data.stations = sts.StationList
+		mock_data.tocs = ts.TOCList
+	})
+}
+
+func mock_station_name(crs string) (string, bool) {
+	for _, st := range mock_data.stations {
+		if st.Crs == crs {
+			return st.Name, true
+		}
+	}
+	return "", false
+}
+
+// serves the Rail Data Marketplace paths, whatever the host and product
+type mock_handler struct {
+	scenario string
+}
+
+func (m mock_handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	load_mock_data()
+	operation := path.Base(path.Dir(r.URL.Path))
+	arg := path.Base(r.URL.Path)
+	query := r.URL.Query()
+
+	// same limits and defaults as the real api
+	time_offset, _ := strconv.Atoi(query.Get("timeOffset"))
+	time_window, err := strconv.Atoi(query.Get("timeWindow"))
+	if err != nil {
+		time_window = max_time_window
+	}
+	if time_offset < min_time_offset || time_offset > max_time_offset || time_window < 0 || time_window > max_time_window {
+		http.Error(w, "Invalid timeOffset or timeWindow", http.StatusBadRequest)
+		return
+	}
+
+	var res any
+	switch operation {
+	case "GetDepartureBoard", "GetDepBoardWithDetails", "GetArrivalBoard", "GetArrBoardWithDetails":
+		if _, ok := mock_station_name(arg); !ok {
+			http.Error(w, "Invalid crs code supplied", http.StatusBadRequest)
+			return
+		}
+		filter_crs := query.Get("filterCrs")
+		filter_type := query.Get("filterType")
+		if filter_crs == "" {
+			filter_crs = "ANY"
+		} else if _, ok := mock_station_name(filter_crs); !ok {
+			http.Error(w, "Invalid filter crs code supplied", http.StatusBadRequest)
+			return
+		}
+		if filter_type == "" {
+			filter_type = "to"
+		}
+		num_rows, err := strconv.Atoi(query.Get("numRows"))
+		if err != nil || num_rows <= 0 {
+			num_rows = 10
+		}
+		res = m.board(arg, filter_crs, filter_type, num_rows, time_offset, time_window, uk_now())
+	case "GetNextDepartures", "GetNextDeparturesWithDetails", "GetFastestDepartures", "GetFastestDeparturesWithDetails":
+		if _, ok := mock_station_name(arg); !ok {
+			http.Error(w, "Invalid crs code supplied", http.StatusBadRequest)
+			return
+		}
+		filter_list := strings.Split(query.Get("filterList"), ",")
+		if len(filter_list) > max_filter_list {
+			http.Error(w, "Too many filter crs codes supplied", http.StatusBadRequest)
+			return
+		}
+		for _, crs := range filter_list {
+			if _, ok := mock_station_name(crs); !ok {
+				http.Error(w, "Invalid filter crs code supplied", http.StatusBadRequest)
+				return
+			}
+		}
+		fastest := strings.HasPrefix(operation, "GetFastest")
+		res = m.departures(arg, filter_list, fastest, time_offset, time_window, uk_now())
+	case "GetServiceDetails":
+		id, err := parse_mock_id(arg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		res = m.service(id, uk_now())
+	default:
+		http.Error(w, "unknown operation "+operation, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// everything needed to make the same service again
+type mock_id struct {
+	crs         string
+	filter_type string
+	filter_crs  string // ANY for no filter
+	day         string // 20060102
+	n           int    // slot of the day
+}
+
+func (id mock_id) String() string {
+	return fmt.Sprintf("MOCK-%s-%s-%s-%s-%d", id.crs, id.filter_type, id.filter_crs, id.day, id.n)
+}
+
+func parse_mock_id(s string) (mock_id, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 6 || parts[0] != "MOCK" {
+		return mock_id{}, errors.New("unknown service ID")
+	}
+	n, err := strconv.Atoi(parts[5])
+	if err != nil {
+		return mock_id{}, errors.New("unknown service ID")
+	}
+	return mock_id{crs: parts[1], filter_type: parts[2], filter_crs: parts[3], day: parts[4], n: n}, nil
+}
+
+// small number from a string, so each station has its own pattern
+func mock_hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// minutes between trains and minutes past midnight of the first one
+func mock_timetable(crs string) (int, int) {
+	h := mock_hash(crs)
+	interval := 5 + int(h%11)
+	return interval, int(h/11) % interval
+}
+
+func mock_hhmm(mins int) string {
+	mins = ((mins % 1440) + 1440) % 1440
+	return fmt.Sprintf("%02d:%02d", mins/60, mins%60)
+}
+
+func (m mock_handler) board(crs, filter_crs, filter_type string, num_rows, time_offset, time_window int, now time.Time) station_board {
+	name, _ := mock_station_name(crs)
+	sb := station_board{
+		GeneratedAt:          now.Format(time.RFC3339),
+		LocationName:         name,
+		Crs:                  crs,
+		FilterType:           filter_type,
+		PlatformAvailable:    true,
+		AreServicesAvailable: true,
+	}
+	if filter_crs != "ANY" {
+		sb.FilterCrs = filter_crs
+		sb.FilterLocationName, _ = mock_station_name(filter_crs)
+	}
+	sb.NrccMessages = m.messages(crs, now)
+
+	interval, offset := mock_timetable(crs)
+	from_mins := max(now.Hour()*60+now.Minute()+time_offset, 0) // the mock day starts at midnight
+	first := (from_mins - offset + interval - 1) / interval     // next slot from the start of the window
+	last := (from_mins + time_window - offset) / interval       // last slot in the window
+	for i := range min(num_rows, max(last-first+1, 0)) {
+		id := mock_id{crs: crs, filter_type: filter_type, filter_crs: filter_crs, day: now.Format("20060102"), n: first + i}
+		s := m.service(id, now).to_service_item(id)
+		if s.ServiceType == "bus" {
+			sb.BusServices = append(sb.BusServices, s)
+		} else {
+			sb.TrainServices = append(sb.TrainServices, s)
+		}
+	}
+	return sb
+}
+
+// next or fastest train to each destination, from boards filtered to each one
+func (m mock_handler) departures(crs string, filter_list []string, fastest bool, time_offset, time_window int, now time.Time) departures_board {
+	name, _ := mock_station_name(crs)
+	db := departures_board{
+		GeneratedAt:          now.Format(time.RFC3339),
+		LocationName:         name,
+		Crs:                  crs,
+		NrccMessages:         m.messages(crs, now),
+		PlatformAvailable:    true,
+		AreServicesAvailable: true,
+	}
+	for _, dest := range filter_list {
+		sb := m.board(crs, dest, "to", 4, time_offset, time_window, now)
+		dep := departure_item{Crs: dest}
+		var best_arr int
+		for i, s := range sb.TrainServices {
+			cp, _ := s.calling_at(dest)
+			arr, ok := best_time(cp.St, cp.Et)
+			if !ok {
+				continue // cancelled
+			}
+			if dep.Service == nil || arr < best_arr {
+				dep.Service = &sb.TrainServices[i]
+				best_arr = arr
+			}
+			if !fastest {
+				break // first one that runs
+			}
+		}
+		db.Departures = append(db.Departures, dep)
+	}
+	return db
+}
+
+// station messages, only in disrupted scenarios and not at every station
+func (m mock_handler) messages(crs string, now time.Time) []nrcc_message {
+	if m.scenario == "on time" || (m.scenario == "mixed" && mock_hash(crs+now.Format("20060102"))%3 != 0) {
+		return nil
+	}
+	var text string
+	switch m.scenario {
+	case "cancellations":
+		text = "<p>Due to a points failure some trains at this station are being cancelled. " +
+			"Disruption is expected until the end of the day.</p>"
+	case "platform changes":
+		text = "<p>Some trains are departing from a different platform.<br/>Please check the departure screens.</p>"
+	default:
+		text = "<p>Disruption caused by a signalling problem. Trains through this station may be delayed by up to " +
+			"20 minutes. <a href=\"https://www.nationalrail.co.uk/\">More details</a> can be found in Latest Travel News.</p>"
+	}
+	return []nrcc_message{{Value: text}}
+}
+
+func (m mock_handler) service(id mock_id, now time.Time) service_details {
+	rng := rand.New(rand.NewPCG(mock_hash(id.String()), mock_hash(m.scenario)))
+	pick_station := func() station {
+		for {
+			st := mock_data.stations[rng.IntN(len(mock_data.stations))]
+			if st.Crs != id.crs && st.Crs != id.filter_crs {
+				return st
+			}
+		}
+	}
+
+	interval, offset := mock_timetable(id.crs)
+	board_mins := offset + id.n*interval
+	now_mins := now.Hour()*60 + now.Minute()
+	if id.day != now.Format("20060102") {
+		now_mins = -1440 // another day, treat everything as in the future
+	}
+
+	// what goes wrong with this train
+	var delay int
+	var cancelled, plat_changed, bus bool
+	roll := rng.IntN(100)
+	switch m.scenario {
+	case "replacement buses":
+		bus = roll < 60
+	case "delays":
+		if roll < 40 {
+			delay = 3 + rng.IntN(25)
+		}
+	case "cancellations":
+		cancelled = roll < 25
+	case "platform changes":
+		plat_changed = roll < 30
+	case "on time":
+	default: // mixed
+		if roll < 15 {
+			delay = 2 + rng.IntN(15)
+		}
+		cancelled = roll >= 15 && roll < 20
+		plat_changed = roll >= 20 && roll < 28
+	}
+
+	toc := mock_data.tocs[rng.IntN(len(mock_data.tocs))]
+	platforms := 2 + int(mock_hash(id.crs)%10)
+	platform := 1 + rng.IntN(platforms)
+
+	prev := make([]station, rng.IntN(5))
+	next := make([]station, 1+rng.IntN(6))
+	for i := range prev {
+		prev[i] = pick_station()
+	}
+	for i := range next {
+		next[i] = pick_station()
+	}
+	// make sure the train actually calls at the filter station
+	if filter, ok := mock_station_name(id.filter_crs); ok {
+		if id.filter_type == "from" {
+			if len(prev) == 0 {
+				prev = append(prev, station{})
+			}
+			prev[rng.IntN(len(prev))] = station{Crs: id.filter_crs, Name: filter}
+		} else {
+			next[rng.IntN(len(next))] = station{Crs: id.filter_crs, Name: filter}
+		}
+	}
+
+	expected := func(mins int) string {
+		if cancelled {
+			return "Cancelled"
+		} else if delay > 0 {
+			return mock_hhmm(mins + delay)
+		}
+		return "On time"
+	}
+
+	name, _ := mock_station_name(id.crs)
+	d := service_details{
+		GeneratedAt:  now.Format(time.RFC3339),
+		ServiceType:  "train",
+		LocationName: name,
+		Crs:          id.crs,
+		Operator:     toc.Name,
+		OperatorCode: toc.Toc,
+		Rsid:         fmt.Sprintf("%s%04d00", toc.Toc, id.n),
+		IsCancelled:  cancelled,
+		Length:       2 + rng.IntN(11),
+		Platform:     strconv.Itoa(platform),
+		Std:          mock_hhmm(board_mins),
+		Etd:          expected(board_mins),
+	}
+	if bus {
+		d.ServiceType = "bus" // no platform or coaches
+		d.Platform = ""
+		d.Length = 0
+	}
+	if len(prev) > 0 {
+		d.Sta = mock_hhmm(board_mins - 1)
+		d.Eta = expected(board_mins - 1)
+	}
+	if cancelled {
+		d.CancelReason = reason(mock_cancel_reasons[rng.IntN(len(mock_cancel_reasons))])
+	} else if delay > 0 {
+		d.DelayReason = reason(mock_delay_reasons[rng.IntN(len(mock_delay_reasons))])
+	}
+	if plat_changed {
+		old := 1 + (platform+rng.IntN(platforms-1))%platforms
+		d.AdhocAlerts = []string{fmt.Sprintf("This train will now depart from platform %d instead of platform %d.", platform, old)}
+	}
+
+	// calling points, times spread out either side of this station
+	prev_points := make([]calling_point, len(prev))
+	mins := board_mins - 1
+	for i := len(prev) - 1; i >= 0; i-- {
+		mins -= 3 + rng.IntN(8)
+		cp := calling_point{LocationName: prev[i].Name, Crs: prev[i].Crs, St: mock_hhmm(mins), IsCancelled: cancelled, Length: d.Length}
+		if !cancelled && mins+delay <= now_mins {
+			cp.At = expected(mins) // already been there
+		} else {
+			cp.Et = expected(mins)
+		}
+		prev_points[i] = cp
+	}
+	next_points := make([]calling_point, len(next))
+	mins = board_mins
+	for i := range next {
+		mins += 3 + rng.IntN(10)
+		next_points[i] = calling_point{LocationName: next[i].Name, Crs: next[i].Crs, St: mock_hhmm(mins), Et: expected(mins), IsCancelled: cancelled, Length: d.Length}
+	}
+	if len(prev_points) > 0 {
+		d.PreviousCallingPoints = []calling_point_set{{CallingPoint: prev_points, ServiceType: d.ServiceType}}
+	}
+	d.SubsequentCallingPoints = []calling_point_set{{CallingPoint: next_points, ServiceType: d.ServiceType}}
+
+	// only some operators report how full their trains are
+	if !bus && mock_hash(toc.Toc)%2 == 0 {
+		d.Formation = &formation{}
+		busy := rng.IntN(100)
+		for i := range d.Length {
+			load := min(max(busy+rng.IntN(41)-20, 0), 100)
+			class := "Standard"
+			if i == 0 {
+				class = "First"
+			}
+			d.Formation.Coaches = append(d.Formation.Coaches,
+				coach{Number: string(rune('A' + i)), CoachClass: class, Loading: load, LoadingSpecified: true})
+		}
+	}
+	return d
+}
+
+// board view of a generated service
+func (d service_details) to_service_item(id mock_id) service_item {
+	s := service_item{
+		Rsid:                    d.Rsid,
+		Sta:                     d.Sta,
+		Eta:                     d.Eta,
+		Std:                     d.Std,
+		Etd:                     d.Etd,
+		Platform:                d.Platform,
+		Operator:                d.Operator,
+		OperatorCode:            d.OperatorCode,
+		IsCancelled:             d.IsCancelled,
+		ServiceType:             d.ServiceType,
+		Length:                  d.Length,
+		Formation:               d.Formation,
+		CancelReason:            d.CancelReason,
+		DelayReason:             d.DelayReason,
+		ServiceID:               id.String(),
+		AdhocAlerts:             d.AdhocAlerts,
+		PreviousCallingPoints:   d.PreviousCallingPoints,
+		SubsequentCallingPoints: d.SubsequentCallingPoints,
+	}
+	first_point := calling_point{LocationName: d.LocationName, Crs: d.Crs}
+	if len(d.PreviousCallingPoints) > 0 {
+		first_point = d.PreviousCallingPoints[0].CallingPoint[0]
+	}
+	next := d.SubsequentCallingPoints[0].CallingPoint
+	last_point := next[len(next)-1]
+	s.Origin = []service_location{{LocationName: first_point.LocationName, Crs: first_point.Crs}}
+	s.Destination = []service_location{{LocationName: last_point.LocationName, Crs: last_point.Crs}}
+
+	// some trains go the long way round, some divide on the way
+	h := mock_hash(s.ServiceID)
+	if len(next) >= 3 && h%5 == 0 {
+		s.Destination[0].Via = "via " + next[len(next)/2].LocationName
+	} else if len(next) >= 2 && h%7 == 0 {
+		other := next[len(next)-2]
+		s.Destination = append(s.Destination, service_location{LocationName: other.LocationName, Crs: other.Crs})
+	}
+	return s
+}
+
+// sends requests straight to a handler, no network needed (also works in wasm)
+type handler_transport struct {
+	handler http.Handler
+}
+
+type handler_response struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *handler_response) Header() http.Header         { return r.header }
+func (r *handler_response) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *handler_response) WriteHeader(status int)      { r.status = status }
+
+func (t handler_transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := &handler_response{header: http.Header{}, status: http.StatusOK}
+	t.handler.ServeHTTP(rec, req)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.status, http.StatusText(rec.status)),
+		StatusCode:    rec.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.header,
+		Body:          io.NopCloser(&rec.body),
+		ContentLength: int64(rec.body.Len()),
+		Request:       req,
+	}, nil
+}
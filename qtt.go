@@ -4,15 +4,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand/v2"
 	"runtime"
 	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"unicode"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
@@ -31,6 +35,22 @@ var dayMapping = map[string]int{
 }
 var days = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
 
+var boardMapping = map[string]string{
+	"Departures": board_dep,
+	"Arrivals":   board_arr,
+	"Next":       board_next,
+	"Fastest":    board_fastest,
+}
+var board_names = []string{"Departures", "Arrivals", "Next", "Fastest"}
+
+var holidayMapping = map[string]string{
+	"None":              "",
+	"England and Wales": "england-and-wales",
+	"Scotland":          "scotland",
+	"Northern Ireland":  "northern-ireland",
+}
+var holiday_names = []string{"None", "England and Wales", "Scotland", "Northern Ireland"}
+
 var qtt_cont_list []fyne.Container
 
 var qts qtt
@@ -46,6 +66,7 @@ type stations struct {
 }
 
 type qtt struct {
+	Version     int          `json:"version"` // see migrate.go
 	Quick_times []quick_time `json:"quick_times"`
 	del_ids     []int
 }
@@ -119,14 +140,28 @@ func GetChosenDaysArray(selectedDays []string) []int {
 	return chosenInts
 }
 
-func time_validator(s string) error {
-	if len(s) != 5 {
-		return errors.New("incorrect length, should be 5 characters")
-	} else if s[2] != ':' {
-		return errors.New("not in correct time format")
+func board_name(kind string) string {
+	for name, val := range boardMapping {
+		if val == kind {
+			return name
+		}
 	}
-	// 15:39
-	return nil
+	return board_names[0]
+}
+
+func holiday_name(region string) string {
+	for name, val := range holidayMapping {
+		if val == region {
+			return name
+		}
+	}
+	return holiday_names[0]
+}
+
+// 15:39, the same check as when the time is used
+func time_validator(s string) error {
+	_, _, err := parse_clock(s)
+	return err
 }
 
 func crs_validator(s string) error {
@@ -148,6 +183,69 @@ func crs_validator(s string) error {
 	}
 }
 
+// whole minutes within [lo,hi], empty for the default
+func minutes_validator(lo, hi int) func(string) error {
+	return func(s string) error {
+		if s == "" {
+			return nil
+		}
+		num, err := strconv.Atoi(s)
+		if err != nil {
+			return errors.New("not a whole number of minutes")
+		} else if num < lo || num > hi {
+			return fmt.Errorf("not within [%d,%d]", lo, hi)
+		}
+		return nil
+	}
+}
+
+// empty entries are 0
+func int_text(num int) string {
+	if num == 0 {
+		return ""
+	}
+	return strconv.Itoa(num)
+}
+
+// comma separated entries, e.g. "BRI, OXF"
+func parse_list(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// destinations of next and fastest boards, e.g. "BRI, OXF"
+func parse_crs_list(s string) []string {
+	return parse_list(s)
+}
+
+func crs_list_validator(s string) error {
+	list := parse_crs_list(s)
+	if len(list) == 0 {
+		return errors.New("needs at least one CRS code")
+	} else if len(list) > max_filter_list {
+		return fmt.Errorf("at most %d CRS codes", max_filter_list)
+	}
+	for _, crs := range list {
+		if err := crs_validator(crs); err != nil {
+			return fmt.Errorf("%s: %w", crs, err)
+		}
+	}
+	return nil
+}
+
+// text of the To entry, a list for next and fastest boards
+func dest_text(qt quick_time) string {
+	if is_departures_board(qt.board_type()) {
+		return strings.Join(qt.Dests, ", ")
+	}
+	return qt.Dest
+}
+
 func qtt_form(new bool, qt quick_time, mywin_addr *fyne.Window, rootURI fyne.URI) *fyne.Container {
 	mywin_obj := *mywin_addr
 	err := json.Unmarshal(resourceStationsJson.StaticContent, &all_stations)
@@ -161,24 +259,106 @@ func qtt_form(new bool, qt quick_time, mywin_addr *fyne.Window, rootURI fyne.URI
 	entry_start.Validator = time_validator
 
 	entry_end := widget.NewEntry()
-	entry_end.SetPlaceHolder("time in 24hr format e.g. 19:00")
+	entry_end.SetPlaceHolder("time in 24hr format e.g. 19:00, before the start time to run past midnight")
 	entry_end.Validator = time_validator
 
+	radio_board := widget.NewRadioGroup(board_names, nil)
+	radio_board.Horizontal = true
+	radio_board.Required = true
+	radio_board.SetSelected(board_names[0])
+
+	// departures need a real origin, arrivals need a real destination
+	// the other end can be any station
 	entry_org := widget.NewEntry()
-	entry_org.SetPlaceHolder("CRS code")
-	entry_org.Validator = crs_validator
+	entry_org.SetPlaceHolder("CRS code, or an asterisk (*) for any origin on arrival boards")
+	entry_org.Validator = func(s string) error {
+		if s == "*" && boardMapping[radio_board.Selected] == board_arr {
+			return nil
+		} else {
+			err := crs_validator(s)
+			return err
+		}
+	}
 
 	entry_dest := widget.NewEntry()
-	entry_dest.SetPlaceHolder("CRS code, or an asterisk (*) for any destination")
+	entry_dest.SetPlaceHolder("CRS code, * for any on departure boards, or a list like BRI, OXF on next and fastest boards")
 	entry_dest.Validator = func(s string) error {
-		if s == "*" {
+		if s == "*" && boardMapping[radio_board.Selected] == board_dep {
 			return nil
+		} else if is_departures_board(boardMapping[radio_board.Selected]) {
+			return crs_list_validator(s)
 		} else {
 			err := crs_validator(s)
 			return err
 		}
 	}
 
+	entry_offset := widget.NewEntry()
+	entry_offset.SetPlaceHolder("minutes from now, e.g. 20 to skip trains you cannot walk to, empty for now")
+	entry_offset.Validator = minutes_validator(min_time_offset, max_time_offset)
+
+	entry_window := widget.NewEntry()
+	entry_window.SetPlaceHolder("minutes after that to show trains for, empty for 120")
+	entry_window.Validator = minutes_validator(1, max_time_window)
+
+	check_hide := widget.NewCheck("only trains, no replacement buses or ferries", nil)
+
+	entry_skip := widget.NewEntry()
+	entry_skip.SetPlaceHolder("dates with no board, e.g. 2026-12-24..2027-01-02, 2027-02-16")
+	entry_skip.Validator = func(s string) error { return date_ranges_validator(parse_list(s)) }
+
+	entry_extra := widget.NewEntry()
+	entry_extra.SetPlaceHolder("dates with a board even on other days, e.g. 2026-11-28")
+	entry_extra.Validator = func(s string) error { return date_ranges_validator(parse_list(s)) }
+
+	entry_priority := widget.NewEntry()
+	entry_priority.SetPlaceHolder("whole number, lower shows first when several entries match, empty for 0")
+	entry_priority.Validator = func(s string) error {
+		if _, err := strconv.Atoi(s); s != "" && err != nil {
+			return errors.New("not a whole number")
+		}
+		return nil
+	}
+
+	select_holidays := widget.NewSelect(holiday_names, nil)
+	select_holidays.SetSelected(holiday_names[0])
+
+	// school terms, leave and so on from a calendar app
+	import_button := widget.NewButtonWithIcon("Import .ics", theme.FileIcon(), func() {
+		file_dialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, mywin_obj)
+				return
+			} else if reader == nil {
+				return // cancelled
+			}
+			defer reader.Close()
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				dialog.ShowError(err, mywin_obj)
+				return
+			}
+			// repeating events for the next two years
+			today := uk_now()
+			ranges, skipped, err := parse_ics(string(content), today.Format(date_format), today.AddDate(2, 0, 0).Format(date_format))
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("cannot read %s: %w", reader.URI().Name(), err), mywin_obj)
+				return
+			}
+			entry_skip.SetText(strings.Join(append(parse_list(entry_skip.Text), ranges...), ", "))
+			if skipped > 0 {
+				dialog.ShowInformation("Info", fmt.Sprintf("%d repeating events were not imported, the app cannot follow how they repeat. Add their dates by hand.", skipped), mywin_obj)
+			}
+		}, mywin_obj)
+		file_dialog.SetFilter(storage.NewExtensionFileFilter([]string{".ics"}))
+		file_dialog.Show()
+	})
+
+	radio_board.OnChanged = func(string) {
+		entry_org.Validate()
+		entry_dest.Validate()
+	}
+
 	checkDays := widget.NewCheckGroup(days, nil)
 
 	if runtime.GOOS == "android" {
@@ -203,13 +383,20 @@ func qtt_form(new bool, qt quick_time, mywin_addr *fyne.Window, rootURI fyne.URI
 		entry_start.SetText(qt.Start)
 		entry_end.SetText(qt.End)
 		entry_org.SetText(qt.Org)
-		entry_dest.SetText(qt.Dest)
+		entry_dest.SetText(dest_text(qt))
 		var selected_days []string
 		for _, v := range qt.Days {
 			selected_days = append(selected_days, days[v])
 		}
 		checkDays.SetSelected(selected_days)
-
+		radio_board.SetSelected(board_name(qt.board_type()))
+		entry_offset.SetText(int_text(qt.Offset))
+		entry_window.SetText(int_text(qt.Window))
+		check_hide.SetChecked(qt.Hide_replacements)
+		entry_skip.SetText(strings.Join(qt.Skip_dates, ", "))
+		entry_extra.SetText(strings.Join(qt.Extra_dates, ", "))
+		select_holidays.SetSelected(holiday_name(qt.Holidays))
+		entry_priority.SetText(int_text(qt.Priority))
 	}
 
 	form := &widget.Form{
@@ -222,6 +409,18 @@ func qtt_form(new bool, qt quick_time, mywin_addr *fyne.Window, rootURI fyne.URI
 			new_qt.Org = entry_org.Text
 			new_qt.Dest = entry_dest.Text
 			new_qt.Days = GetChosenDaysArray(checkDays.Selected)
+			new_qt.Board = boardMapping[radio_board.Selected]
+			if is_departures_board(new_qt.Board) {
+				new_qt.Dest = ""
+				new_qt.Dests = parse_crs_list(entry_dest.Text)
+			}
+			new_qt.Offset, _ = strconv.Atoi(entry_offset.Text) // empty is 0
+			new_qt.Window, _ = strconv.Atoi(entry_window.Text)
+			new_qt.Hide_replacements = check_hide.Checked
+			new_qt.Skip_dates = parse_list(entry_skip.Text)
+			new_qt.Extra_dates = parse_list(entry_extra.Text)
+			new_qt.Holidays = holidayMapping[select_holidays.Selected]
+			new_qt.Priority, _ = strconv.Atoi(entry_priority.Text) // empty is 0
 			if qts.check_exist(id) {
 				qts.replace_by_id(id, new_qt)
 			} else {
@@ -235,6 +434,7 @@ func qtt_form(new bool, qt quick_time, mywin_addr *fyne.Window, rootURI fyne.URI
 			} else {
 				success_msg := dialog.NewInformation("Info", "entry saved successfully", mywin_obj)
 				success_msg.Show()
+				wake_scheduler() // its window may open sooner
 			}
 
 		},
@@ -245,22 +445,38 @@ func qtt_form(new bool, qt quick_time, mywin_addr *fyne.Window, rootURI fyne.URI
 			entry_start.SetText(qt.Start)
 			entry_end.SetText(qt.End)
 			entry_org.SetText(qt.Org)
-			entry_dest.SetText(qt.Dest)
+			entry_dest.SetText(dest_text(qt))
 			var selected_days []string
 			for _, v := range qt.Days {
 				selected_days = append(selected_days, days[v])
 			}
 			checkDays.SetSelected(selected_days)
+			radio_board.SetSelected(board_name(qt.board_type()))
+			entry_offset.SetText(int_text(qt.Offset))
+			entry_window.SetText(int_text(qt.Window))
+			check_hide.SetChecked(qt.Hide_replacements)
+			entry_skip.SetText(strings.Join(qt.Skip_dates, ", "))
+			entry_extra.SetText(strings.Join(qt.Extra_dates, ", "))
+			select_holidays.SetSelected(holiday_name(qt.Holidays))
+			entry_priority.SetText(int_text(qt.Priority))
 		},
 		SubmitText: "Save",
 		CancelText: "Cancel",
 	}
 
+	form.Append("Board", radio_board)
 	form.Append("Start time", entry_start)
 	form.Append("End time", entry_end)
 	form.Append("From station", entry_org)
 	form.Append("To station", entry_dest)
 	form.Append("Days", checkDays)
+	form.Append("Priority", entry_priority)
+	form.Append("Start in (mins)", entry_offset)
+	form.Append("Show for (mins)", entry_window)
+	form.Append("Hide buses", check_hide)
+	form.Append("Skip dates", container.NewBorder(nil, nil, nil, import_button, entry_skip))
+	form.Append("Extra dates", entry_extra)
+	form.Append("Skip bank holidays", select_holidays)
 
 	del_button := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
 
@@ -293,7 +509,7 @@ func qtt_init(mywin_addr *fyne.Window, rootURI fyne.URI) *container.Scroll {
 	var err error
 	_, qts, err = load_json("qtt.json", rootURI)
 	if err != nil {
-		dialog.NewError(err, mywin)
+		show_load_error(err, mywin, rootURI)
 	}
 
 	vb := container.NewVBox()
@@ -308,7 +524,76 @@ func qtt_init(mywin_addr *fyne.Window, rootURI fyne.URI) *container.Scroll {
 		vb.Add(&qtt_cont_list[len(qtt_cont_list)-1])
 	})
 
-	main_border := container.NewBorder(nil, new_button, nil, nil, vb)
+	preview_button := widget.NewButton("preview", func() { show_preview(mywin, rootURI) })
+
+	main_border := container.NewBorder(nil, container.NewGridWithColumns(2, new_button, preview_button), nil, nil, vb)
 	return container.NewScroll(main_border)
 
 }
+
+// which saved entries the home tab would show at a day and time, without waiting for it
+func show_preview(mywin fyne.Window, rootURI fyne.URI) {
+	s, _, err := load_json("settings.json", rootURI)
+	if err != nil {
+		dialog.ShowError(err, mywin)
+		return
+	}
+	_, saved, err := load_json("qtt.json", rootURI)
+	if err != nil {
+		dialog.ShowError(err, mywin)
+		return
+	}
+
+	// the coming week, so date exceptions count too
+	today := uk_now()
+	var day_names []string
+	for i := range 7 {
+		day_names = append(day_names, today.AddDate(0, 0, i).Format("Mon 2 Jan"))
+	}
+	select_day := widget.NewSelect(day_names, nil)
+	entry_time := widget.NewEntry()
+	entry_time.SetPlaceHolder("time in 24hr format e.g. 07:00")
+	entry_time.Validator = time_validator
+	result := widget.NewLabel("")
+	result.Wrapping = fyne.TextWrapWord
+
+	update := func() {
+		day := today.AddDate(0, 0, max(slices.Index(day_names, select_day.Selected), 0))
+		at, err := at_clock(day, entry_time.Text, true)
+		if err != nil {
+			result.SetText(err.Error())
+			return
+		}
+		result.SetText(preview_text(match_quick_times(saved.Quick_times, at, s.Max_boards)))
+	}
+	select_day.OnChanged = func(string) { update() }
+	entry_time.OnChanged = func(string) { update() }
+	select_day.SetSelected(day_names[0])
+	entry_time.SetText(today.Format("15:04"))
+
+	form := widget.NewForm(widget.NewFormItem("Day", select_day), widget.NewFormItem("Time", entry_time))
+	preview := dialog.NewCustom("Preview saved entries", "Close", container.NewBorder(form, nil, nil, nil, container.NewVScroll(result)), mywin)
+	preview.Resize(fyne.NewSize(500, 450))
+	preview.Show()
+}
+
+// shown entries in order, then the others and why
+func preview_text(matches []match_result) string {
+	var shown, hidden []string
+	for _, m := range matches {
+		name := fmt.Sprintf("%s to %s", m.qt.Org, dest_text(m.qt))
+		if m.shown {
+			shown = append(shown, fmt.Sprintf("%d. %s, priority %d", len(shown)+1, name, m.qt.Priority))
+		} else {
+			hidden = append(hidden, fmt.Sprintf("%s: %s", name, m.reason))
+		}
+	}
+	text := "Shown:\n" + strings.Join(shown, "\n")
+	if len(shown) == 0 {
+		text = "Shown: nothing, not in specified time frames"
+	}
+	if len(hidden) > 0 {
+		text += "\n\nNot shown:\n" + strings.Join(hidden, "\n")
+	}
+	return text
+}
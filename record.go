@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// this code file records raw api responses and replays them, for bug reports
+// a recording is one file per session in the storage root, a json line for the session
+// then one for each request, so saving a response only appends to the file
+
+const (
+	recording_prefix string = "recording-"
+	recording_ext    string = ".jsonl"
+	redacted         string = "REDACTED"
+
+	max_recording_size int = 20 << 20 // bytes, recording stops after this
+)
+
+// everything in one session
+type recording struct {
+	Source    string           `json:"source"` // source_rdm, source_darwin or "demo"
+	Started   time.Time        `json:"started"`
+	Exchanges []recorded_round `json:"exchanges,omitempty"` // own lines in the file
+}
+
+// one request and its response, keys and tokens are never saved
+type recorded_round struct {
+	Time    time.Time `json:"time"`
+	Method  string    `json:"method"`
+	URL     string    `json:"url"`
+	Action  string    `json:"action,omitempty"`  // SOAPAction
+	Request string    `json:"request,omitempty"` // soap body, token redacted
+	Status  int       `json:"status"`
+	Body    string    `json:"body"`
+}
+
+// requests with the same key are the same question to the api
+func (r recorded_round) key() string {
+	return r.Method + " " + r.URL + " " + r.Action + " " + r.Request
+}
+
+// read the request body and put it back so it can still be sent
+func request_body(req *http.Request) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return string(body), nil
+}
+
+// request as it is saved, without the secret
+func redacted_round(req *http.Request, secret string) (recorded_round, error) {
+	body, err := request_body(req)
+	if err != nil {
+		return recorded_round{}, err
+	}
+	if secret != "" {
+		body = strings.ReplaceAll(body, secret, redacted)
+	}
+	return recorded_round{Method: req.Method, URL: req.URL.String(), Action: req.Header.Get("SOAPAction"), Request: body}, nil
+}
+
+// ----- recording -----
+
+// one recording per run of the app, shared by all refreshes
+var session_recording struct {
+	mu    sync.Mutex
+	fname string
+	size  int  // bytes written so far
+	full  bool // max_recording_size reached
+}
+
+// http.RoundTripper that saves every response it passes on
+type recording_transport struct {
+	inner   http.RoundTripper
+	source  string
+	secret  string // darwin token, the rdm keys only go in headers which are not saved
+	rootURI fyne.URI
+}
+
+func (t recording_transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	round, err := redacted_round(req, t.secret)
+	if err != nil {
+		return nil, err
+	}
+	res, err := t.inner.RoundTrip(req)
+	if err != nil {
+		return nil, err // nothing to replay
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	round.Time = time.Now()
+	round.Status = res.StatusCode
+	round.Body = string(body)
+	if err := t.save(round); err != nil {
+		log.Printf("cannot save recording: %v", err)
+	}
+	return res, nil
+}
+
+func (t recording_transport) save(round recorded_round) error {
+	session_recording.mu.Lock()
+	defer session_recording.mu.Unlock()
+	if session_recording.full {
+		return nil
+	}
+	if session_recording.fname == "" {
+		fname := recording_prefix + round.Time.Format("20060102-150405") + recording_ext
+		header, err := json.Marshal(recording{Source: t.source, Started: round.Time})
+		if err != nil {
+			return err
+		}
+		header = append(header, '\n')
+		if err := write_file(header, fname, t.rootURI); err != nil {
+			return err
+		}
+		session_recording.fname = fname
+		session_recording.size = len(header)
+	}
+
+	line, err := json.Marshal(round)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if session_recording.size+len(line) > max_recording_size {
+		session_recording.full = true
+		log.Printf("%s is over %d MB, not recording any more", session_recording.fname, max_recording_size>>20)
+		return nil
+	}
+	myURI, err := storage.Child(t.rootURI, session_recording.fname)
+	if err != nil {
+		return err
+	}
+	appender, err := storage.Appender(myURI)
+	if err != nil {
+		return err
+	}
+	defer appender.Close()
+	if _, err := appender.Write(line); err != nil {
+		return err
+	}
+	session_recording.size += len(line)
+	return nil
+}
+
+// what a client sends requests through
+func transport_of(client *http.Client) http.RoundTripper {
+	if client.Transport == nil {
+		return http.DefaultTransport
+	}
+	return client.Transport
+}
+
+// names of the saved recordings, newest first
+func list_recordings(rootURI fyne.URI) []string {
+	uris, err := storage.List(rootURI)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, u := range uris {
+		// .json is a recording from before they were appended to
+		if strings.HasPrefix(u.Name(), recording_prefix) && (u.Extension() == recording_ext || u.Extension() == ".json") {
+			names = append(names, u.Name())
+		}
+	}
+	slices.Sort(names)
+	slices.Reverse(names)
+	return names
+}
+
+func load_recording(fname string, rootURI fyne.URI) (recording, error) {
+	var rec recording
+	content, err := read_file(fname, rootURI)
+	if err != nil {
+		return rec, err
+	}
+	if !strings.HasSuffix(fname, recording_ext) {
+		err = json.Unmarshal(content, &rec) // all in one
+		return rec, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		return rec, err
+	}
+	for i, line := range lines[1:] {
+		var round recorded_round
+		if err := json.Unmarshal([]byte(line), &round); err != nil {
+			// the app may have stopped halfway through a line
+			log.Printf("%s: ignoring line %d and after: %v", fname, i+2, err)
+			break
+		}
+		rec.Exchanges = append(rec.Exchanges, round)
+	}
+	return rec, nil
+}
+
+// ----- replay -----
+
+// the recording being replayed, kept between refreshes so repeated requests move on
+var active_replay struct {
+	mu        sync.Mutex
+	fname     string
+	source    string
+	transport replay_transport
+}
+
+// source that reads the recording chosen in settings instead of the network
+// the keys only need to be there, they are not in the recording
+func replay_source(s settings, rootURI fyne.URI) (DepartureSource, error) {
+	const secret string = "replay"
+	active_replay.mu.Lock()
+	defer active_replay.mu.Unlock()
+	if active_replay.fname != s.Replay {
+		rec, err := load_recording(s.Replay, rootURI)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open recording %s: %w", s.Replay, err)
+		}
+		active_replay.fname = s.Replay
+		active_replay.source = rec.Source
+		active_replay.transport = new_replay_transport(rec, secret)
+	}
+
+	client := &http.Client{Transport: active_replay.transport}
+	var src DepartureSource = rdm_source{dep_key: secret, arr_key: secret, svc_key: secret, client: client}
+	if active_replay.source == source_darwin {
+		src = darwin_source{token: secret, client: client}
+	}
+	// memory only, a replay should not replace the boards saved for startup
+	return new_cached_source(src, "replay-"+s.Replay, s, nil), nil
+}
+
+// http.RoundTripper that answers from a recording instead of the network
+// a request asked more than once gets the recorded answers in turn
+type replay_transport struct {
+	mu     *sync.Mutex
+	rounds map[string][]recorded_round
+	next   map[string]int
+	secret string // what the replaying source puts where the token was
+}
+
+func new_replay_transport(rec recording, secret string) replay_transport {
+	rounds := map[string][]recorded_round{}
+	for _, round := range rec.Exchanges {
+		rounds[round.key()] = append(rounds[round.key()], round)
+	}
+	return replay_transport{mu: &sync.Mutex{}, rounds: rounds, next: map[string]int{}, secret: secret}
+}
+
+func (t replay_transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	asked, err := redacted_round(req, t.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	rounds := t.rounds[asked.key()]
+	if len(rounds) == 0 {
+		t.mu.Unlock()
+		return nil, errors.New("this request is not in the recording")
+	}
+	round := rounds[t.next[asked.key()]%len(rounds)]
+	t.next[asked.key()]++
+	t.mu.Unlock()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", round.Status, http.StatusText(round.Status)),
+		StatusCode:    round.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          io.NopCloser(strings.NewReader(round.Body)),
+		ContentLength: int64(len(round.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// this code file decides which quick times show a board at a given time
+
+const date_format string = "2006-01-02"
+
+// https://www.gov.uk/bank-holidays.json, bundled as bank_holidays.json
+type holiday_calendar map[string]struct {
+	Division string `json:"division"`
+	Events   []struct {
+		Title string `json:"title"`
+		Date  string `json:"date"`
+	} `json:"events"`
+}
+
+// region -> date -> name of the holiday
+var bank_holidays struct {
+	mu     sync.Mutex
+	dates  map[string]map[string]string
+	last   map[string]string // last date known in each region
+	warned map[string]bool   // regions already warned about running out of dates
+}
+
+func load_bank_holidays() {
+	bank_holidays.dates = map[string]map[string]string{}
+	bank_holidays.last = map[string]string{}
+	bank_holidays.warned = map[string]bool{}
+	var cal holiday_calendar
+	if err := json.Unmarshal(resourceBankHolidaysJson.StaticContent, &cal); err != nil {
+		log.Printf("cannot read bank_holidays.json, no bank holidays are skipped: %v", err)
+		return
+	}
+	for name, division := range cal {
+		bank_holidays.dates[name] = map[string]string{}
+		for _, ev := range division.Events {
+			bank_holidays.dates[name][ev.Date] = ev.Title
+			bank_holidays.last[name] = max(bank_holidays.last[name], ev.Date)
+		}
+	}
+}
+
+func bank_holiday(region, date string) (string, bool) {
+	bank_holidays.mu.Lock()
+	defer bank_holidays.mu.Unlock()
+	if bank_holidays.dates == nil {
+		load_bank_holidays()
+	}
+	if last := bank_holidays.last[region]; date > last && !bank_holidays.warned[region] {
+		// the calendar only goes a year or two ahead, see go:generate in main.go
+		log.Printf("bank holidays of %s are only known until %q, update bank_holidays.json", region, last)
+		bank_holidays.warned[region] = true
+	}
+	title, ok := bank_holidays.dates[region][date]
+	return title, ok
+}
+
+// a date like 2026-12-24, or a range like 2026-12-24..2027-01-02
+func parse_date_range(s string) (string, string, error) {
+	from, to, is_range := strings.Cut(strings.TrimSpace(s), "..")
+	if !is_range {
+		to = from
+	}
+	for _, d := range []string{from, to} {
+		if _, err := time.Parse(date_format, d); err != nil {
+			return "", "", fmt.Errorf("%s is not a date like 2026-12-24", d)
+		}
+	}
+	if to < from {
+		return "", "", fmt.Errorf("%s ends before it starts", s)
+	}
+	return from, to, nil
+}
+
+func date_ranges_validator(list []string) error {
+	for _, s := range list {
+		if _, _, err := parse_date_range(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iso dates sort as strings, so no need to parse them again
+func date_in(ranges []string, date string) bool {
+	for _, s := range ranges {
+		from, to, err := parse_date_range(s)
+		if err == nil && date >= from && date <= to {
+			return true
+		}
+	}
+	return false
+}
+
+// whether qt has a window starting on day, and why not
+// skipped dates win over extra dates, which win over bank holidays and weekdays
+func (qt quick_time) runs_on(day time.Time) (bool, string) {
+	date := day.Format(date_format)
+	if date_in(qt.Skip_dates, date) {
+		return false, date + " is a skipped date"
+	}
+	if date_in(qt.Extra_dates, date) {
+		return true, date + " is an extra date"
+	}
+	if qt.Holidays != "" {
+		if title, ok := bank_holiday(qt.Holidays, date); ok {
+			return false, date + " is a bank holiday, " + title
+		}
+	}
+	if !slices.Contains(qt.Days, int(day.Weekday())) {
+		return false, day.Weekday().String() + " is not one of its days"
+	}
+	return true, ""
+}
+
+// whether now is within the window of qt
+func in_window(qt quick_time, now time.Time) (bool, error) {
+	ok, _, err := check_window(qt, now)
+	return ok, err
+}
+
+// whether now is within the window of qt, and why not
+func check_window(qt quick_time, now time.Time) (bool, string, error) {
+	_, ok, reason, err := open_window(qt, now)
+	return ok, reason, err
+}
+
+// when the window of qt open at now ends, and if none is open why not
+// a window ending at or before its start runs past midnight, its days are the days it starts on
+func open_window(qt quick_time, now time.Time) (time.Time, bool, string, error) {
+	now = now.In(london)
+	reason := fmt.Sprintf("outside %s to %s", qt.Start, qt.End)
+	// started today, or yesterday and still going
+	for i, day := range []time.Time{now, now.AddDate(0, 0, -1)} {
+		ok, why := qt.runs_on(day)
+		if !ok {
+			if i == 0 {
+				reason = why // not the day, whatever the time
+			}
+			continue
+		}
+		start, err := at_clock(day, qt.Start, true)
+		if err != nil {
+			return time.Time{}, false, "", err
+		}
+		end, err := at_clock(day, qt.End, false)
+		if err != nil {
+			return time.Time{}, false, "", err
+		}
+		if !end.After(start) {
+			end, err = at_clock(day.AddDate(0, 0, 1), qt.End, false)
+			if err != nil {
+				return time.Time{}, false, "", err
+			}
+		}
+
+		if now.After(start) && now.Before(end) {
+			return end, true, "", nil
+		}
+	}
+	return time.Time{}, false, reason, nil
+}
+
+// what happened to one quick time at a given time
+type match_result struct {
+	qt     quick_time
+	shown  bool
+	reason string // why it is not shown
+}
+
+// which quick times show a board at now, shown ones first by priority
+// only max_boards are shown, 0 or less for the default
+// an entry with broken times is not shown, the others still are
+func match_quick_times(all []quick_time, now time.Time, max_boards int) []match_result {
+	var shown, hidden []match_result
+	for _, qt := range all {
+		ok, reason, err := check_window(qt, now)
+		if err != nil {
+			hidden = append(hidden, match_result{qt: qt, reason: "cannot be checked, " + err.Error()})
+		} else if ok {
+			shown = append(shown, match_result{qt: qt, shown: true})
+		} else {
+			hidden = append(hidden, match_result{qt: qt, reason: reason})
+		}
+	}
+	slices.SortStableFunc(shown, func(a, b match_result) int {
+		return a.qt.Priority - b.qt.Priority
+	})
+
+	if max_boards <= 0 {
+		max_boards = default_max_boards
+	}
+	for i := max_boards; i < len(shown); i++ {
+		shown[i].shown = false
+		shown[i].reason = fmt.Sprintf("lower priority, only %d boards show", max_boards)
+	}
+	return append(shown, hidden...)
+}
+
+// whether any quick time is within its window, broken ones never are
+func any_window_open(all []quick_time, now time.Time) bool {
+	for _, qt := range all {
+		if ok, _ := in_window(qt, now); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// the first window closing after now, false if none is open
+func next_window_end(all []quick_time, now time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, qt := range all {
+		end, ok, _, err := open_window(qt, now)
+		if err == nil && ok && (!found || end.Before(next)) {
+			next, found = end, true
+		}
+	}
+	return next, found
+}
+
+// the first window opening after now, looking a week ahead
+func next_window_start(all []quick_time, now time.Time) (time.Time, bool) {
+	now = now.In(london)
+	var next time.Time
+	found := false
+	for _, qt := range all {
+		for i := range 8 {
+			day := now.AddDate(0, 0, i)
+			if ok, _ := qt.runs_on(day); !ok {
+				continue
+			}
+			start, err := at_clock(day, qt.Start, true)
+			if err != nil {
+				break
+			}
+			if start.After(now) {
+				if !found || start.Before(next) {
+					next, found = start, true
+				}
+				break
+			}
+		}
+	}
+	return next, found
+}
+
+// ----- iCalendar -----
+
+// dates of the events in an .ics file, as date ranges for Skip_dates
+// only whole days matter, an event on any part of a day covers that day
+// events ending before today are left out, days next to each other are merged into one range
+// repeating events are expanded up to horizon, skipped counts the ones with rules too complex to follow
+func parse_ics(content, today, horizon string) (ranges []string, skipped int, err error) {
+	// long lines are folded onto lines starting with a space
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\n ", "")
+	content = strings.ReplaceAll(content, "\n\t", "")
+
+	var spans [][2]string
+	var start, end, rule string
+	var excluded []string
+	var start_date_only, in_event bool
+	for _, line := range strings.Split(content, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		prop, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(prop) {
+		case "BEGIN":
+			if value == "VEVENT" {
+				in_event, start, end, rule, excluded = true, "", "", "", nil
+			}
+		case "DTSTART":
+			if in_event {
+				start_date_only = len(value) == 8 || strings.Contains(params, "VALUE=DATE")
+				start = ics_date(value, params, false)
+			}
+		case "DTEND":
+			if in_event {
+				end = ics_date(value, params, true)
+			}
+		case "RRULE":
+			if in_event {
+				rule = value
+			}
+		case "EXDATE":
+			if in_event {
+				for _, v := range strings.Split(value, ",") {
+					excluded = append(excluded, ics_date(v, params, false))
+				}
+			}
+		case "END":
+			if value != "VEVENT" || !in_event {
+				continue
+			}
+			in_event = false
+			if start == "" {
+				return nil, 0, errors.New("event without a start date")
+			}
+			if end == "" || (start_date_only && end < start) {
+				end = start
+			}
+
+			starts := []string{start}
+			if rule != "" {
+				if starts, ok = expand_rrule(rule, start, horizon); !ok {
+					skipped++
+					continue
+				}
+			}
+			first, _ := time.Parse(date_format, start)
+			last, _ := time.Parse(date_format, end)
+			days := int(last.Sub(first).Hours()/24 + 0.5)
+			for _, from := range starts {
+				if slices.Contains(excluded, from) {
+					continue
+				}
+				t, _ := time.Parse(date_format, from)
+				spans = append(spans, [2]string{from, t.AddDate(0, 0, days).Format(date_format)})
+			}
+		}
+	}
+	if len(spans) == 0 && skipped > 0 {
+		return nil, skipped, fmt.Errorf("only repeating events found, and their rules are not supported (%d)", skipped)
+	} else if len(spans) == 0 {
+		return nil, 0, errors.New("no events found")
+	}
+	ranges = merge_date_ranges(spans, today)
+	if len(ranges) == 0 {
+		return nil, skipped, errors.New("all events are in the past")
+	}
+	return ranges, skipped, nil
+}
+
+// from..to spans as Skip_dates, from today on
+// spans that overlap or follow on from each other become one range
+func merge_date_ranges(spans [][2]string, today string) []string {
+	slices.SortFunc(spans, func(a, b [2]string) int { return strings.Compare(a[0], b[0]) })
+	var merged [][2]string
+	for _, span := range spans {
+		if span[1] < today {
+			continue
+		}
+		span[0] = max(span[0], today)
+		if n := len(merged); n > 0 && span[0] <= day_after(merged[n-1][1]) {
+			merged[n-1][1] = max(merged[n-1][1], span[1])
+			continue
+		}
+		merged = append(merged, span)
+	}
+
+	ranges := make([]string, 0, len(merged))
+	for _, span := range merged {
+		if span[0] == span[1] {
+			ranges = append(ranges, span[0])
+		} else {
+			ranges = append(ranges, span[0]+".."+span[1])
+		}
+	}
+	return ranges
+}
+
+func day_after(date string) string {
+	t, _ := time.Parse(date_format, date)
+	return t.AddDate(0, 0, 1).Format(date_format)
+}
+
+// start dates of a repeating event, up to horizon
+// only rules repeating on the same day every few days, weeks, months or years can be followed
+func expand_rrule(rule, start, horizon string) ([]string, bool) {
+	first, err := time.Parse(date_format, start)
+	if err != nil {
+		return nil, false
+	}
+	var freq, until, by_day string
+	interval, count := 1, 0
+	for _, part := range strings.Split(rule, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			freq = strings.ToUpper(value)
+		case "INTERVAL":
+			if interval, err = strconv.Atoi(value); err != nil || interval < 1 {
+				return nil, false
+			}
+		case "COUNT":
+			if count, err = strconv.Atoi(value); err != nil || count < 1 {
+				return nil, false
+			}
+		case "UNTIL":
+			until = ics_date(value, "", false)
+			if until == "" {
+				return nil, false
+			}
+		case "WKST":
+			// only matters with more than one day a week
+		case "BYDAY":
+			by_day = value
+		case "BYMONTH":
+			if value != strconv.Itoa(int(first.Month())) {
+				return nil, false
+			}
+		case "BYMONTHDAY":
+			if value != strconv.Itoa(first.Day()) {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+
+	// calendar apps spell out the day of weekly events
+	if by_day != "" && (freq != "WEEKLY" || !strings.EqualFold(by_day, first.Weekday().String()[:2])) {
+		return nil, false
+	}
+
+	next := map[string]func(time.Time, int) time.Time{
+		"DAILY":   func(t time.Time, n int) time.Time { return t.AddDate(0, 0, n) },
+		"WEEKLY":  func(t time.Time, n int) time.Time { return t.AddDate(0, 0, 7*n) },
+		"MONTHLY": func(t time.Time, n int) time.Time { return t.AddDate(0, n, 0) },
+		"YEARLY":  func(t time.Time, n int) time.Time { return t.AddDate(n, 0, 0) },
+	}[freq]
+	if next == nil || (freq == "MONTHLY" && first.Day() > 28) || (freq == "YEARLY" && first.Month() == 2 && first.Day() == 29) {
+		return nil, false // not every month or year has the day
+	}
+
+	var starts []string
+	for i := 0; count == 0 || i < count; i++ {
+		date := next(first, i*interval).Format(date_format)
+		if (until != "" && date > until) || date > horizon {
+			break
+		}
+		starts = append(starts, date)
+	}
+	return starts, true
+}
+
+// uk date of an ics DATE or DATE-TIME value, "" if it cannot be read
+// end times are exclusive, so an end at midnight is the day before
+func ics_date(value, params string, is_end bool) string {
+	var t time.Time
+	var err error
+	switch {
+	case len(value) == 8:
+		t, err = time.ParseInLocation("20060102", value, london)
+		if err != nil {
+			return ""
+		} else if is_end {
+			t = t.AddDate(0, 0, -1)
+		}
+		return t.Format(date_format)
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+	default:
+		loc := london
+		if _, tzid, ok := strings.Cut(params, "TZID="); ok {
+			tzid, _, _ = strings.Cut(tzid, ";")
+			if l, err := time.LoadLocation(tzid); err == nil {
+				loc = l
+			}
+		}
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+	}
+	if err != nil {
+		return ""
+	}
+	t = t.In(london)
+	if is_end && t.Hour() == 0 && t.Minute() == 0 {
+		t = t.Add(-time.Minute)
+	}
+	return t.Format(date_format)
+}
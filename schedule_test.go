@@ -0,0 +1,121 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExpandRrule(t *testing.T) {
+	cases := []struct {
+		rule, start, horizon string
+		want                 []string // nil if the rule cannot be followed
+	}{
+		{"FREQ=DAILY;COUNT=3", "2026-12-30", "2030-01-01", []string{"2026-12-30", "2026-12-31", "2027-01-01"}},
+		{"FREQ=WEEKLY;BYDAY=MO;UNTIL=20261201T000000Z", "2026-11-09", "2030-01-01",
+			[]string{"2026-11-09", "2026-11-16", "2026-11-23", "2026-11-30"}},
+		{"FREQ=WEEKLY;INTERVAL=2;COUNT=3", "2026-11-09", "2030-01-01", []string{"2026-11-09", "2026-11-23", "2026-12-07"}},
+		{"FREQ=MONTHLY;UNTIL=20270301", "2026-12-15", "2030-01-01", []string{"2026-12-15", "2027-01-15", "2027-02-15"}},
+		{"FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=24", "2026-12-24", "2028-12-31", []string{"2026-12-24", "2027-12-24", "2028-12-24"}},
+		{"FREQ=YEARLY", "2026-12-24", "2027-12-23", []string{"2026-12-24"}}, // stops at the horizon
+
+		{"FREQ=WEEKLY;BYDAY=MO,TU", "2026-11-09", "2030-01-01", nil},
+		{"FREQ=WEEKLY;BYDAY=TU", "2026-11-09", "2030-01-01", nil}, // not the day it starts on
+		{"FREQ=MONTHLY", "2026-10-31", "2030-01-01", nil},         // not every month has a 31st
+		{"FREQ=YEARLY", "2028-02-29", "2030-01-01", nil},
+		{"FREQ=MONTHLY;BYSETPOS=-1", "2026-10-01", "2030-01-01", nil},
+		{"FREQ=HOURLY", "2026-10-01", "2030-01-01", nil},
+	}
+	for _, c := range cases {
+		got, ok := expand_rrule(c.rule, c.start, c.horizon)
+		if ok != (c.want != nil) || !slices.Equal(got, c.want) {
+			t.Errorf("expand_rrule(%s, %s) = %v %v, want %v", c.rule, c.start, got, ok, c.want)
+		}
+	}
+}
+
+// lines of an .ics file, each event between BEGIN and END
+func ics(lines ...string) string {
+	return "BEGIN:VCALENDAR\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+}
+
+func TestParseIcs(t *testing.T) {
+	const today, horizon string = "2026-10-17", "2028-10-17"
+	cases := []struct {
+		name    string
+		content string
+		want    []string
+		skipped int
+	}{
+		{"all day events end the day before DTEND", ics(
+			"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261026", "DTEND;VALUE=DATE:20261031", "END:VEVENT",
+			"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261224", "DTEND;VALUE=DATE:20261225", "END:VEVENT",
+		), []string{"2026-10-26..2026-10-30", "2026-12-24"}, 0},
+		{"timed events cover their uk days", ics(
+			"BEGIN:VEVENT", "DTSTART:20261224T230000Z", "DTEND:20261225T000000Z", "END:VEVENT",
+			"BEGIN:VEVENT", "DTSTART;TZID=Europe/London:20270102T090000", "END:VEVENT",
+		), []string{"2026-12-24", "2027-01-02"}, 0},
+		{"folded lines", ics(
+			"BEGIN:VEVENT", "SUMMARY:Half", " term", "DTSTART;VALUE=DATE:2026", " 1026", "END:VEVENT",
+		), []string{"2026-10-26"}, 0},
+		{"endless daily event is one range up to the horizon", ics(
+			"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20240101", "RRULE:FREQ=DAILY", "END:VEVENT",
+		), []string{"2026-10-17..2028-10-17"}, 0},
+		{"past events are left out", ics(
+			"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20251224", "DTEND;VALUE=DATE:20251227", "END:VEVENT",
+			"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261015", "DTEND;VALUE=DATE:20261020", "END:VEVENT",
+		), []string{"2026-10-17..2026-10-19"}, 0},
+		{"weekly with an exception, touching events merged", ics(
+			"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261102", "RRULE:FREQ=WEEKLY;COUNT=4", "EXDATE;VALUE=DATE:20261109", "END:VEVENT",
+			"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261117", "DTEND;VALUE=DATE:20261119", "END:VEVENT",
+		), []string{"2026-11-02", "2026-11-16..2026-11-18", "2026-11-23"}, 0},
+		{"unsupported rules are counted", ics(
+			"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261102", "RRULE:FREQ=WEEKLY;BYDAY=MO,WE", "END:VEVENT",
+			"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261224", "END:VEVENT",
+		), []string{"2026-12-24"}, 1},
+	}
+	for _, c := range cases {
+		got, skipped, err := parse_ics(c.content, today, horizon)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		} else if !slices.Equal(got, c.want) || skipped != c.skipped {
+			t.Errorf("%s: got %v with %d skipped, want %v with %d", c.name, got, skipped, c.want, c.skipped)
+		}
+	}
+
+	for _, content := range []string{
+		ics(),
+		ics("BEGIN:VEVENT", "DTSTART;VALUE=DATE:20251224", "END:VEVENT"),
+		ics("BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261102", "RRULE:FREQ=SECONDLY", "END:VEVENT"),
+		ics("BEGIN:VEVENT", "SUMMARY:no start", "END:VEVENT"),
+	} {
+		if got, _, err := parse_ics(content, today, horizon); err == nil {
+			t.Errorf("parse_ics(%q) = %v, want an error", content, got)
+		}
+	}
+}
+
+func TestMatchQuickTimesKeepsGoingPastBrokenEntries(t *testing.T) {
+	every_day := []int{0, 1, 2, 3, 4, 5, 6}
+	all := []quick_time{
+		{Id: 1, Start: "07:00", End: "09:00", Days: every_day, Priority: 2},
+		{Id: 2, Start: "7am", End: "09:00", Days: every_day},
+		{Id: 3, Start: "07:30", End: "08:30", Days: every_day, Priority: 1},
+		{Id: 4, Start: "07:45", End: "08:15", Days: every_day, Priority: 3},
+		{Id: 5, Start: "17:00", End: "19:00", Days: every_day},
+	}
+	now := utc(10, 19, 7, 0) // 08:00 BST
+	var shown, hidden []int
+	for _, m := range match_quick_times(all, now, 2) {
+		if m.shown {
+			shown = append(shown, m.qt.Id)
+		} else if m.reason == "" {
+			t.Errorf("entry %d is hidden without a reason", m.qt.Id)
+		} else {
+			hidden = append(hidden, m.qt.Id)
+		}
+	}
+	if !slices.Equal(shown, []int{3, 1}) || !slices.Equal(hidden, []int{4, 2, 5}) {
+		t.Errorf("shown %v and hidden %v, want [3 1] and [4 2 5]", shown, hidden)
+	}
+}
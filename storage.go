@@ -10,11 +10,33 @@ import (
 )
 
 func save_json(mystruct any, fname string, rootURI fyne.URI) error {
+	// files with a schema are always saved at the current version
+	switch v := mystruct.(type) {
+	case settings:
+		v.Version = settings_version
+		mystruct = v
+	case qtt:
+		v.Version = qtt_version
+		mystruct = v
+	}
 	jsonData, err := json.Marshal(mystruct)
 	if err != nil {
 		return err
 	}
 
+	// keep the last good file to restore if this one breaks
+	if _, ok := schema_versions[fname]; ok {
+		if old, err := read_file(fname, rootURI); err == nil && json.Valid(old) {
+			if err := write_file(old, fname+".bak", rootURI); err != nil {
+				return err
+			}
+		}
+	}
+
+	return write_file(jsonData, fname, rootURI)
+}
+
+func write_file(content []byte, fname string, rootURI fyne.URI) error {
 	myURI, err := storage.Child(rootURI, fname)
 	if err != nil {
 		return err
@@ -26,7 +48,7 @@ func save_json(mystruct any, fname string, rootURI fyne.URI) error {
 	}
 	defer writeCloser.Close()
 
-	_, err = io.Writer.Write(writeCloser, jsonData)
+	_, err = io.Writer.Write(writeCloser, content)
 	if err != nil {
 		return err
 	}
@@ -34,13 +56,28 @@ func save_json(mystruct any, fname string, rootURI fyne.URI) error {
 	return nil
 }
 
+func read_file(fname string, rootURI fyne.URI) ([]byte, error) {
+	myURI, err := storage.Child(rootURI, fname)
+	if err != nil {
+		return nil, err
+	}
+
+	readCloser, err := storage.Reader(myURI)
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	return io.ReadAll(readCloser)
+}
+
 func load_json(fname string, rootURI fyne.URI) (settings, qtt, error) {
 	default_strs := map[string]string{
-		"settings.json": `{"freq":60,"key":"ZF2QTLjOalPE2KrbeoUsOarJ7ic4XQHJPnR9eiSHR9I4j0A0","desired_len":5}`,
-		"qtt.json":      `{"quick_times":[{}]}`,
+		"settings.json": `{"version":1,"freq":60,"min_freq":15,"max_freq":600,"timeout":10,"key":"ZF2QTLjOalPE2KrbeoUsOarJ7ic4XQHJPnR9eiSHR9I4j0A0","arr_key":"","svc_key":"","source":"rdm","darwin_token":"","demo":false,"demo_scenario":"mixed","record":false,"replay":"","desired_len":5,"max_boards":2,"cache_ttl":30,"columns":[]}`,
+		"qtt.json":      `{"version":1,"quick_times":[{}]}`,
 	}
-	mysettings := settings{Freq: 60, Key: "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"}
-	myqtt := qtt{Quick_times: make([]quick_time, 0), del_ids: make([]int, 0)}
+	mysettings := settings{Version: settings_version, Freq: 60, Min_freq: default_min_freq, Max_freq: default_max_freq, Timeout: default_timeout, Key: default_key, Cache_ttl: default_cache_ttl, Max_boards: default_max_boards}
+	myqtt := qtt{Version: qtt_version, Quick_times: make([]quick_time, 0), del_ids: make([]int, 0)}
 
 	myURI, err := storage.Child(rootURI, fname)
 	if err != nil {
@@ -76,6 +113,10 @@ func load_json(fname string, rootURI fyne.URI) (settings, qtt, error) {
 		if err != nil {
 			return mysettings, myqtt, err
 		}
+		content, err = migrate(fname, content, rootURI)
+		if err != nil {
+			return mysettings, myqtt, err
+		}
 		switch fname {
 		case "settings.json":
 			err = json.Unmarshal(content, &mysettings)
@@ -83,7 +124,7 @@ func load_json(fname string, rootURI fyne.URI) (settings, qtt, error) {
 			err = json.Unmarshal(content, &myqtt)
 		}
 		if err != nil {
-			return mysettings, myqtt, err
+			return mysettings, myqtt, &broken_file_error{fname: fname, err: err}
 		}
 		return mysettings, myqtt, nil
 	}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+)
+
+// this code file handles uk time, all schedule maths uses it
+
+// railway time, with summer time from the tz database
+var london = load_london()
+
+func load_london() *time.Location {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		// only without a tz database, see tzdata.go
+		log.Printf("cannot load Europe/London, using UTC: %v", err)
+		return time.UTC
+	}
+	return loc
+}
+
+// current time in the UK
+func uk_now() time.Time {
+	return time.Now().In(london)
+}
+
+// hours and minutes of a "15:39" time, two digits each, also checks the forms
+func parse_clock(hhmm string) (int, int, error) {
+	if len(hhmm) != 5 || hhmm[2] != ':' {
+		return 0, 0, errors.New("time not in 24hr format: " + hhmm)
+	}
+	for _, i := range []int{0, 1, 3, 4} {
+		if hhmm[i] < '0' || hhmm[i] > '9' {
+			return 0, 0, errors.New("time not in 24hr format: " + hhmm)
+		}
+	}
+	hours, _ := strconv.Atoi(hhmm[0:2])
+	if hours > 23 {
+		return 0, 0, errors.New("invalid hours: " + hhmm)
+	}
+	mins, _ := strconv.Atoi(hhmm[3:5])
+	if mins > 59 {
+		return 0, 0, errors.New("invalid minutes: " + hhmm)
+	}
+	return hours, mins, nil
+}
+
+// a "15:39" time on the day of day, in the UK
+// times skipped when the clocks go forward are moved on by an hour,
+// times that happen twice when they go back are the first or the last one
+func at_clock(day time.Time, hhmm string, first bool) (time.Time, error) {
+	hours, mins, err := parse_clock(hhmm)
+	if err != nil {
+		return time.Time{}, err
+	}
+	day = day.In(london)
+	t := time.Date(day.Year(), day.Month(), day.Day(), hours, mins, 0, 0, london)
+	other := t.Add(time.Hour)
+	if first {
+		other = t.Add(-time.Hour)
+	}
+	if other.Hour() == t.Hour() && other.Minute() == t.Minute() {
+		return other, nil
+	}
+	return t, nil
+}
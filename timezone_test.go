@@ -0,0 +1,117 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// both nights the clocks change in 2026, each a sunday
+// spring: 01:00 GMT becomes 02:00 BST, autumn: 02:00 BST becomes 01:00 GMT
+var (
+	spring_night = time.Date(2026, 3, 29, 12, 0, 0, 0, time.UTC)
+	autumn_night = time.Date(2026, 10, 25, 12, 0, 0, 0, time.UTC)
+)
+
+func utc(month time.Month, day, hour, min int) time.Time {
+	return time.Date(2026, month, day, hour, min, 0, 0, time.UTC)
+}
+
+func TestAtClock(t *testing.T) {
+	cases := []struct {
+		day   time.Time
+		hhmm  string
+		first bool
+		want  time.Time
+	}{
+		// spring, 01:00 to 01:59 never happen and move on an hour
+		{spring_night, "00:30", true, utc(3, 29, 0, 30)},
+		{spring_night, "00:30", false, utc(3, 29, 0, 30)},
+		{spring_night, "01:00", true, utc(3, 29, 1, 0)},
+		{spring_night, "01:00", false, utc(3, 29, 1, 0)},
+		{spring_night, "01:30", true, utc(3, 29, 1, 30)},
+		{spring_night, "01:30", false, utc(3, 29, 1, 30)},
+		{spring_night, "02:00", true, utc(3, 29, 1, 0)},
+		{spring_night, "02:00", false, utc(3, 29, 1, 0)},
+
+		// autumn, 01:00 to 01:59 happen twice, in BST then in GMT
+		{autumn_night, "00:30", true, utc(10, 24, 23, 30)},
+		{autumn_night, "00:30", false, utc(10, 24, 23, 30)},
+		{autumn_night, "01:00", true, utc(10, 25, 0, 0)},
+		{autumn_night, "01:00", false, utc(10, 25, 1, 0)},
+		{autumn_night, "01:30", true, utc(10, 25, 0, 30)},
+		{autumn_night, "01:30", false, utc(10, 25, 1, 30)},
+		{autumn_night, "02:00", true, utc(10, 25, 2, 0)},
+		{autumn_night, "02:00", false, utc(10, 25, 2, 0)},
+	}
+	for _, c := range cases {
+		got, err := at_clock(c.day, c.hhmm, c.first)
+		if err != nil {
+			t.Errorf("at_clock(%s, %s, %v): %v", c.day.Format(date_format), c.hhmm, c.first, err)
+		} else if !got.Equal(c.want) {
+			t.Errorf("at_clock(%s, %s, %v) = %v, want %v", c.day.Format(date_format), c.hhmm, c.first, got.UTC(), c.want)
+		}
+	}
+}
+
+func TestCheckWindowOverClockChange(t *testing.T) {
+	sunday := []int{0}
+	cases := []struct {
+		qt   quick_time
+		now  time.Time
+		want bool
+	}{
+		// spring, 00:30 to 03:00 is an hour and a half long
+		{quick_time{Start: "00:30", End: "03:00", Days: sunday}, utc(3, 29, 0, 15), false},
+		{quick_time{Start: "00:30", End: "03:00", Days: sunday}, utc(3, 29, 0, 45), true},  // 00:45 GMT
+		{quick_time{Start: "00:30", End: "03:00", Days: sunday}, utc(3, 29, 1, 30), true},  // 02:30 BST
+		{quick_time{Start: "00:30", End: "03:00", Days: sunday}, utc(3, 29, 2, 15), false}, // 03:15 BST
+
+		// autumn, 00:30 to 03:00 is three and a half hours long
+		{quick_time{Start: "00:30", End: "03:00", Days: sunday}, utc(10, 24, 23, 15), false},
+		{quick_time{Start: "00:30", End: "03:00", Days: sunday}, utc(10, 24, 23, 45), true}, // 00:45 BST
+		{quick_time{Start: "00:30", End: "03:00", Days: sunday}, utc(10, 25, 0, 45), true},  // first 01:45
+		{quick_time{Start: "00:30", End: "03:00", Days: sunday}, utc(10, 25, 1, 45), true},  // second 01:45
+		{quick_time{Start: "00:30", End: "03:00", Days: sunday}, utc(10, 25, 3, 15), false}, // 03:15 GMT
+
+		// a window within the repeated hour runs from the first start to the last end
+		{quick_time{Start: "01:15", End: "01:45", Days: sunday}, utc(10, 25, 0, 0), false},
+		{quick_time{Start: "01:15", End: "01:45", Days: sunday}, utc(10, 25, 0, 30), true},
+		{quick_time{Start: "01:15", End: "01:45", Days: sunday}, utc(10, 25, 1, 30), true},
+		{quick_time{Start: "01:15", End: "01:45", Days: sunday}, utc(10, 25, 1, 50), false},
+
+		// saturday night to sunday morning, past midnight and the change
+		{quick_time{Start: "23:00", End: "02:30", Days: []int{6}}, utc(10, 24, 22, 30), true}, // 23:30 BST
+		{quick_time{Start: "23:00", End: "02:30", Days: []int{6}}, utc(10, 25, 2, 15), true},  // 02:15 GMT
+		{quick_time{Start: "23:00", End: "02:30", Days: []int{6}}, utc(10, 25, 2, 45), false},
+	}
+	for _, c := range cases {
+		got, reason, err := check_window(c.qt, c.now)
+		if err != nil {
+			t.Errorf("check_window(%s to %s, %v): %v", c.qt.Start, c.qt.End, c.now, err)
+		} else if got != c.want {
+			t.Errorf("check_window(%s to %s, %v) = %v (%s), want %v", c.qt.Start, c.qt.End, c.now, got, reason, c.want)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	for _, hhmm := range []string{"00:00", "07:05", "23:59"} {
+		if _, _, err := parse_clock(hhmm); err != nil {
+			t.Errorf("parse_clock(%s): %v", hhmm, err)
+		}
+		if err := time_validator(hhmm); err != nil {
+			t.Errorf("time_validator(%s): %v", hhmm, err)
+		}
+	}
+	for _, hhmm := range []string{"", "7:00", "07:00:00", "0700 ", "-1:30", "+1:30", "12:-5", "1 :30", "25:00", "24:00", "12:60", "ab:cd", "07.00"} {
+		if _, _, err := parse_clock(hhmm); err == nil {
+			t.Errorf("parse_clock(%q) is fine, want an error", hhmm)
+		}
+		if err := time_validator(hhmm); err == nil {
+			t.Errorf("time_validator(%q) is fine, want an error", hhmm)
+		}
+		if _, err := at_clock(spring_night, hhmm, true); err == nil {
+			t.Errorf("at_clock(%q) is fine, want an error", hhmm)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build android || ios || windows || (js && wasm)
+
+package main
+
+// these platforms may have no tz database, so carry one in the binary
+import _ "time/tzdata"